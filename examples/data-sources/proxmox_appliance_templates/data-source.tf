@@ -0,0 +1,5 @@
+# List the system container templates available to a node
+data "proxmox_appliance_templates" "system" {
+  node    = "node_one"
+  section = "system"
+}
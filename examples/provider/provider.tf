@@ -17,4 +17,7 @@ provider "proxmox" {
   #host     = "https://192.168.1.111:8006/api2/json"
   #username = "myuser@pve"
   #password = "somepass"
+
+  # Only needed to write snippets
+  #ssh_private_key = file("~/.ssh/id_ed25519")
 }
@@ -0,0 +1,5 @@
+# Containers can be imported by specifying the node and vmid.
+# The ostemplate, password and ssh_public_keys are not kept in the container
+# config, the values configured on the first apply after import are recorded
+# without replacing the container.
+terraform import proxmox_node_container.web node_one@200
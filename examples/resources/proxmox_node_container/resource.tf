@@ -0,0 +1,44 @@
+# An unprivileged debian container
+resource "proxmox_node_container" "web" {
+  id              = 200
+  node            = "node_one"
+  ostemplate      = "local:vztmpl/debian-12-standard_12.2-1_amd64.tar.zst"
+  hostname        = "web"
+  cores           = 2
+  memory          = 1024
+  unprivileged    = true
+  ssh_public_keys = [file("~/.ssh/id_ed25519.pub")]
+  start_on_create = true
+
+  features {
+    nesting = true
+    keyctl  = true
+    fuse    = true
+  }
+
+  rootfs {
+    storage = "local-lvm"
+    size_gb = 8
+  }
+
+  mount_point {
+    path    = "/srv/data"
+    storage = "local-lvm"
+    size_gb = 32
+    backup  = true
+  }
+
+  mount_point {
+    path      = "/mnt/media"
+    host_path = "/tank/media"
+    readonly  = true
+  }
+
+  network {
+    name    = "eth0"
+    bridge  = "vmbr0"
+    ip      = "192.168.1.50/24"
+    gateway = "192.168.1.1"
+    vlan    = 10
+  }
+}
@@ -1,2 +1,4 @@
-# Order can be imported by specifying the numeric identifier.
+# Content can be imported by specifying the node and volume id. The download
+# source is not known to proxmox, the configured url and checksum are recorded
+# on the first apply without downloading the content again.
 terraform import proxmox_node_storage_content.ubuntu_iso node_one@local:iso/ubuntu.iso
@@ -1,12 +1,68 @@
 # Download storage content
 resource "proxmox_node_storage_content" "ubuntu_iso" {
-  node     = "node_one"
-  storage  = "ds9/local"
+  storage  = "node_one/local"
   filename = "ubuntu-22.iso"
 
   iso {
-    url               = "https://releases.ubuntu.com/22.04.1/ubuntu-22.04.1-live-server-amd64.iso"
-    checksum          = "10f19c5b2b8d6db711582e0e27f5116296c34fe4b313ba45f9b201a5007056cb"
-    checksum_alorithm = "sha256"
+    url                = "https://releases.ubuntu.com/22.04.1/ubuntu-22.04.1-live-server-amd64.iso"
+    checksum           = "10f19c5b2b8d6db711582e0e27f5116296c34fe4b313ba45f9b201a5007056cb"
+    checksum_algorithm = "sha256"
+  }
+}
+
+# Keep the current iso until a new release has been downloaded, the filename
+# has to change with the url so both can exist at the same time
+resource "proxmox_node_storage_content" "ubuntu_release_iso" {
+  storage  = "node_one/local"
+  filename = "ubuntu-22.04.3.iso"
+
+  iso {
+    url = "https://releases.ubuntu.com/22.04.3/ubuntu-22.04.3-live-server-amd64.iso"
+  }
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+# Download a container template from the appliance index
+resource "proxmox_node_storage_content" "debian_template" {
+  storage  = "node_one/local"
+  filename = "debian-12-standard_12.2-1_amd64.tar.zst"
+
+  vztmpl {
+    appliance = "debian-12-standard_12.2-1_amd64.tar.zst"
+  }
+}
+
+# Upload a local iso, it is replaced whenever the local file changes
+resource "proxmox_node_storage_content" "installer" {
+  storage  = "node_one/local"
+  filename = "installer.iso"
+
+  source_file {
+    path    = "${path.module}/build/installer.iso"
+    content = "iso"
+  }
+}
+
+# Download a cloud image to import as a virtual machine disk
+resource "proxmox_node_storage_content" "debian_cloud" {
+  storage  = "node_one/local"
+  filename = "debian-12-genericcloud-amd64.qcow2"
+
+  import {
+    url = "https://cloud.debian.org/images/cloud/bookworm/latest/debian-12-genericcloud-amd64.qcow2"
+  }
+}
+
+# Write cloud-init user data onto a snippets storage for cicustom, it is
+# written again over SSH whenever the content changes
+resource "proxmox_node_storage_content" "user_data" {
+  storage  = "node_one/local"
+  filename = "user-data.yaml"
+
+  snippet {
+    content = file("${path.module}/cloud-init/user-data.yaml")
   }
 }
@@ -0,0 +1,2 @@
+# Snapshots can be imported by specifying the node, vmid and snapshot name.
+terraform import proxmox_node_virtual_machine_snapshot.pre_upgrade node_one@555/pre_upgrade
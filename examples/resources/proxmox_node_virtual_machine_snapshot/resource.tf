@@ -0,0 +1,8 @@
+# Snapshot a virtual machine before a risky change
+resource "proxmox_node_virtual_machine_snapshot" "pre_upgrade" {
+  node        = "node_one"
+  vmid        = 555
+  name        = "pre_upgrade"
+  description = "Before upgrading the database"
+  include_ram = true
+}
@@ -0,0 +1,132 @@
+// Package apierr classifies the errors returned by the proxmox api client.
+//
+// The client only surfaces failed requests as strings of the form
+// "non 200: <code> <message>", this package parses those back into the
+// HTTP status and the PVE message so callers can match on the kind of failure
+// instead of comparing error strings.
+package apierr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrLocked           = errors.New("locked")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrTimeout          = errors.New("timeout")
+	ErrConflict         = errors.New("conflict")
+)
+
+// ErrGuestNotFound is returned when the VM or container config does not
+// exist, errors.Is(err, ErrNotFound) also matches it.
+var ErrGuestNotFound = fmt.Errorf("guest %w", ErrNotFound)
+
+var statusRegex = regexp.MustCompile(`^non 200: (\d{3}) ?(.*)$`)
+
+type matcher struct {
+	kind  error
+	regex *regexp.Regexp
+}
+
+// matchers are checked in order, locks are checked before timeouts as PVE
+// reports a lock that could not be acquired as "got timeout". Not found only
+// matches missing guest configs, volumes and snapshots, a missing storage or
+// node is a misconfiguration and not a deleted resource.
+var matchers = []matcher{
+	{ErrLocked, regexp.MustCompile(`(?i)(can't lock file|is locked|lock-\d+\.conf)`)},
+	{ErrGuestNotFound, regexp.MustCompile(`(?i)(configuration file '[^']*/(qemu-server|lxc)/\d+\.conf' does not exist|unable to find configuration file for (VM|CT) \d+)`)},
+	{ErrNotFound, regexp.MustCompile(`(?i)(volume '[^']+' does not exist|no such (logical )?volume|volume_size_info on '[^']+' failed|snapshot '[^']+' does not exist|no such resource '[^']+')`)},
+	{ErrConflict, regexp.MustCompile(`(?i)(already exists|already used|duplicate)`)},
+	{ErrPermissionDenied, regexp.MustCompile(`(?i)(permission check failed|permission denied|no ticket|authentication failure)`)},
+	{ErrTimeout, regexp.MustCompile(`(?i)(timeout|timed out)`)},
+}
+
+// Error is a failed proxmox api request.
+type Error struct {
+	StatusCode int
+	Message    string
+	// Kind is one of the Err* sentinels or nil if the failure was not
+	// recognized.
+	Kind error
+
+	err error
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Is allows errors.Is(err, apierr.ErrNotFound) style checks.
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && errors.Is(e.Kind, target)
+}
+
+// Parse converts an error returned by the proxmox api client into an *Error.
+// Errors that did not come from a failed request are returned unchanged.
+func Parse(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return err
+	}
+
+	matches := statusRegex.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return err
+	}
+	code, convErr := strconv.Atoi(matches[1])
+	if convErr != nil {
+		return err
+	}
+
+	apiErr = &Error{
+		StatusCode: code,
+		Message:    strings.TrimSpace(matches[2]),
+		err:        err,
+	}
+	apiErr.Kind = Kind(apiErr.StatusCode, apiErr.Message)
+	return apiErr
+}
+
+// Is reports whether err, once parsed, is of the given kind.
+func Is(err error, kind error) bool {
+	return errors.Is(Parse(err), kind)
+}
+
+// Kind classifies a PVE status code and message, returning one of the Err*
+// sentinels or nil. A code of 0 classifies the message alone, such as a task
+// exit status.
+func Kind(code int, message string) error {
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrPermissionDenied
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrTimeout
+	}
+	for _, m := range matchers {
+		if m.regex.MatchString(message) {
+			return m.kind
+		}
+	}
+	// pveproxy uses 596 when the connection to the target node times out
+	if code == 596 {
+		return ErrTimeout
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package apierr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  string
+		want error
+	}{
+		// Missing guests
+		{
+			name: "pve7 vm config missing",
+			err:  "non 200: 500 Configuration file 'nodes/pve/qemu-server/100.conf' does not exist",
+			want: ErrGuestNotFound,
+		},
+		{
+			name: "pve8 container config missing",
+			err:  "non 200: 500 Configuration file 'nodes/pve1/lxc/101.conf' does not exist",
+			want: ErrGuestNotFound,
+		},
+		{
+			name: "pve8 vm delete missing",
+			err:  "non 200: 500 unable to find configuration file for VM 100 on node 'pve'",
+			want: ErrGuestNotFound,
+		},
+		{
+			name: "pve9 vm status missing",
+			err:  "non 200: 500 unable to find configuration file for VM 104 - no such machine",
+			want: ErrGuestNotFound,
+		},
+		// Missing volumes, snapshots and ha resources
+		{
+			name: "pve7 volume missing",
+			err:  "non 200: 500 volume_size_info on 'local:iso/debian-11.iso' failed",
+			want: ErrNotFound,
+		},
+		{
+			name: "pve8 volume missing",
+			err:  "non 200: 500 volume 'local:vztmpl/debian-12-standard_12.2-1_amd64.tar.zst' does not exist",
+			want: ErrNotFound,
+		},
+		{
+			name: "pve8 logical volume missing",
+			err:  "non 200: 500 no such logical volume pve/vm-100-disk-0",
+			want: ErrNotFound,
+		},
+		{
+			name: "pve9 snapshot missing",
+			err:  "non 200: 500 snapshot 'before-upgrade' does not exist",
+			want: ErrNotFound,
+		},
+		{
+			name: "ha resource missing",
+			err:  "non 200: 500 no such resource 'vm:100'",
+			want: ErrNotFound,
+		},
+		{
+			name: "http not found",
+			err:  "non 200: 404 Not Found",
+			want: ErrNotFound,
+		},
+		// Locks
+		{
+			name: "pve7 lock timeout",
+			err:  "non 200: 500 can't lock file '/var/lock/qemu-server/lock-100.conf' - got timeout",
+			want: ErrLocked,
+		},
+		{
+			name: "pve8 vm locked",
+			err:  "non 200: 500 VM is locked (backup)",
+			want: ErrLocked,
+		},
+		{
+			name: "pve9 container locked",
+			err:  "non 200: 500 CT is locked (snapshot)",
+			want: ErrLocked,
+		},
+		// Conflicts, permissions and timeouts
+		{
+			name: "vmid in use",
+			err:  "non 200: 500 unable to create VM 100 - VM 100 already exists on node 'pve2'",
+			want: ErrConflict,
+		},
+		{
+			name: "permission check",
+			err:  "non 200: 403 Permission check failed (/vms/100, VM.Allocate)",
+			want: ErrPermissionDenied,
+		},
+		{
+			name: "ticket expired",
+			err:  "non 200: 401 No ticket",
+			want: ErrPermissionDenied,
+		},
+		{
+			name: "node unreachable",
+			err:  "non 200: 596 Connection timed out",
+			want: ErrTimeout,
+		},
+		{
+			name: "proxy timeout",
+			err:  "non 200: 596 Broken pipe",
+			want: ErrTimeout,
+		},
+		// Failures that must not be mistaken for a deleted resource
+		{
+			name: "vm not running",
+			err:  "non 200: 500 VM 100 not running",
+			want: nil,
+		},
+		{
+			name: "missing storage",
+			err:  "non 200: 500 storage 'nfs-backup' does not exist",
+			want: nil,
+		},
+		{
+			name: "missing file on node",
+			err:  "non 200: 500 unable to open file '/etc/pve/nodes/pve3/qemu-server/100.conf.tmp' - No such file or directory",
+			want: nil,
+		},
+		{
+			name: "unknown node",
+			err:  "non 200: 595 no such node 'pve3'",
+			want: nil,
+		},
+		{
+			name: "parameter error",
+			err:  "non 200: 400 Parameter verification failed.",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := Parse(errors.New(tt.err))
+			var apiErr *Error
+			if !errors.As(parsed, &apiErr) {
+				t.Fatalf("Parse(%q) did not return an *Error", tt.err)
+			}
+			if apiErr.Kind != tt.want {
+				t.Errorf("Kind = %v, want %v", apiErr.Kind, tt.want)
+			}
+		})
+	}
+}
+
+func TestIs(t *testing.T) {
+	guestMissing := errors.New("non 200: 500 Configuration file 'nodes/pve/qemu-server/100.conf' does not exist")
+	volumeMissing := errors.New("non 200: 500 volume 'local:iso/a.iso' does not exist")
+
+	if !Is(guestMissing, ErrGuestNotFound) {
+		t.Error("missing guest config should be ErrGuestNotFound")
+	}
+	if !Is(guestMissing, ErrNotFound) {
+		t.Error("ErrGuestNotFound should also match ErrNotFound")
+	}
+	if !Is(volumeMissing, ErrNotFound) {
+		t.Error("missing volume should be ErrNotFound")
+	}
+	if Is(volumeMissing, ErrGuestNotFound) {
+		t.Error("missing volume should not be ErrGuestNotFound")
+	}
+	if Is(errors.New("connection refused"), ErrNotFound) {
+		t.Error("errors not from a request should not match")
+	}
+	if Is(nil, ErrNotFound) {
+		t.Error("nil should not match")
+	}
+}
+
+func TestParse(t *testing.T) {
+	err := Parse(errors.New("non 200: 500 CT is locked (backup)"))
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatal("expected an *Error")
+	}
+	if apiErr.StatusCode != 500 {
+		t.Errorf("StatusCode = %d, want 500", apiErr.StatusCode)
+	}
+	if apiErr.Message != "CT is locked (backup)" {
+		t.Errorf("Message = %q", apiErr.Message)
+	}
+	if Parse(apiErr) != apiErr {
+		t.Error("parsing an *Error should return it unchanged")
+	}
+
+	plain := errors.New("parameter error: vmid")
+	if Parse(plain) != plain {
+		t.Error("errors not from a failed request should be returned unchanged")
+	}
+}
@@ -0,0 +1,165 @@
+// Package remote writes files onto a node over SSH. The proxmox API has no
+// endpoint writing snippets, they can only be copied onto the storage
+// directory of the node.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// DefaultPort is the port sshd listens on by default.
+const DefaultPort = 22
+
+// exitNotExist is the exit status of Sha256 for a missing file.
+const exitNotExist = 44
+
+type Client struct {
+	config *ssh.ClientConfig
+	port   int
+}
+
+// New returns a client authenticating as username with the password and the
+// PEM encoded private key, each is only used when set. Keys of a running
+// ssh-agent are tried last.
+//
+// Host keys are not verified, same as the TLS certificate of the API.
+func New(username string, password string, privateKey string) (*Client, error) {
+	methods := []ssh.AuthMethod{}
+	if privateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+		if err != nil {
+			return nil, fmt.Errorf("parsing private key: %w", err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+	if password != "" {
+		methods = append(methods, ssh.Password(password))
+	}
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		methods = append(methods, ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			conn, err := net.Dial("unix", socket)
+			if err != nil {
+				return nil, err
+			}
+			return agent.NewClient(conn).Signers()
+		}))
+	}
+	return &Client{
+		config: &ssh.ClientConfig{
+			User:            username,
+			Auth:            methods,
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		},
+		port: DefaultPort,
+	}, nil
+}
+
+// SetPort changes the port connected to on every host.
+func (c *Client) SetPort(port int) {
+	c.port = port
+}
+
+// Write streams r into the file at name on host. The content is written next
+// to the file first and only moved over it once it matches the hex encoded
+// sha256 sum, readers never see a partially written file.
+func (c *Client) Write(ctx context.Context, host string, name string, r io.Reader, sum string) error {
+	dir := path.Dir(name)
+	cmd := fmt.Sprintf(
+		`set -e; mkdir -p %s; tmp=$(mktemp %s); trap 'rm -f "$tmp"' EXIT; cat > "$tmp"; `+
+			`echo %s"  $tmp" | sha256sum -c --status || { echo "checksum mismatch" >&2; exit 1; }; chmod 0644 "$tmp"; mv -f "$tmp" %s`,
+		quote(dir), quote(path.Join(dir, "."+path.Base(name)+".XXXXXX")), quote(sum), quote(name),
+	)
+	_, err := c.run(ctx, host, cmd, r)
+	return err
+}
+
+// Remove removes the file at name on host, a missing file is not an error.
+func (c *Client) Remove(ctx context.Context, host string, name string) error {
+	_, err := c.run(ctx, host, "rm -f "+quote(name), nil)
+	return err
+}
+
+// Sha256 returns the hex encoded sha256 sum of the file at name on host, the
+// error wraps os.ErrNotExist when there is no such file.
+func (c *Client) Sha256(ctx context.Context, host string, name string) (string, error) {
+	cmd := fmt.Sprintf(`test -f %s || exit %d; sha256sum < %s`, quote(name), exitNotExist, quote(name))
+	out, err := c.run(ctx, host, cmd, nil)
+	var exit *ssh.ExitError
+	if errors.As(err, &exit) && exit.ExitStatus() == exitNotExist {
+		return "", fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unexpected sha256sum output %q", out)
+	}
+	return fields[0], nil
+}
+
+// run runs cmd on host with stdin as its input and returns its output.
+func (c *Client) run(ctx context.Context, host string, cmd string, stdin io.Reader) (string, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(c.port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	sconn, chans, reqs, err := ssh.NewClientConn(conn, addr, c.config)
+	if err != nil {
+		conn.Close()
+		return "", err
+	}
+	client := ssh.NewClient(sconn, chans, reqs)
+	defer client.Close()
+
+	// Closing the connection aborts the command when ctx is done
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			client.Close()
+		case <-stop:
+		}
+	}()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdin = stdin
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if err := session.Run(cmd); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// quote quotes s as a single shell word.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,228 @@
+package remote
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer starts an sshd accepting password for user and running exec
+// requests with the local shell.
+func testServer(t *testing.T, user string, password string) (host string, port int) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == user && string(pass) == password {
+				return nil, nil
+			}
+			return nil, errors.New("access denied")
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn, config)
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				req.Reply(true, nil)
+				cmd := exec.Command("sh", "-c", string(req.Payload[4:]))
+				cmd.Stdin = channel
+				cmd.Stdout = channel
+				cmd.Stderr = channel.Stderr()
+				status := uint32(0)
+				if err := cmd.Run(); err != nil {
+					status = 1
+					var exit *exec.ExitError
+					if errors.As(err, &exit) {
+						status = uint32(exit.ExitCode())
+					}
+				}
+				payload := make([]byte, 4)
+				binary.BigEndian.PutUint32(payload, status)
+				channel.SendRequest("exit-status", false, payload)
+				return
+			}
+		}()
+	}
+}
+
+func testClient(t *testing.T) (*Client, string) {
+	t.Helper()
+	host, port := testServer(t, "root", "secret")
+	c, err := New("root", "secret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetPort(port)
+	return c, host
+}
+
+func sha256Hex(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(h[:])
+}
+
+func TestWrite(t *testing.T) {
+	c, host := testClient(t)
+	dir := filepath.Join(t.TempDir(), "it's", "snippets")
+	name := filepath.Join(dir, "user-data.yaml")
+
+	for _, content := range []string{"#cloud-config\n", "#cloud-config\npackages: [vim]\n"} {
+		err := c.Write(context.Background(), host, name, strings.NewReader(content), sha256Hex(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("content = %q, want %q", got, content)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("snippets = %v, temporary files must be moved over the file", entries)
+	}
+}
+
+func TestWriteChecksumMismatch(t *testing.T) {
+	c, host := testClient(t)
+	dir := t.TempDir()
+	name := filepath.Join(dir, "user-data.yaml")
+	if err := os.WriteFile(name, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A short read arrives as a complete file on the node
+	err := c.Write(context.Background(), host, name, strings.NewReader("trunc"), sha256Hex("truncated"))
+	if err == nil {
+		t.Fatal("expected an error for content not matching the checksum")
+	}
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "old" {
+		t.Errorf("content = %q, the file must be kept", got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("entries = %v, the temporary file must be removed", entries)
+	}
+}
+
+func TestSha256(t *testing.T) {
+	c, host := testClient(t)
+	name := filepath.Join(t.TempDir(), "user-data.yaml")
+	if err := os.WriteFile(name, []byte("#cloud-config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := c.Sha256(context.Background(), host, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := sha256Hex("#cloud-config\n"); sum != want {
+		t.Errorf("sha256 = %q, want %q", sum, want)
+	}
+
+	_, err = c.Sha256(context.Background(), host, name+".missing")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("err = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	c, host := testClient(t)
+	name := filepath.Join(t.TempDir(), "user-data.yaml")
+	if err := os.WriteFile(name, []byte("#cloud-config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := c.Remove(context.Background(), host, name); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("stat = %v, want the file removed", err)
+		}
+	}
+}
+
+func TestAuthFailure(t *testing.T) {
+	host, port := testServer(t, "root", "secret")
+	t.Setenv("SSH_AUTH_SOCK", "")
+	c, err := New("root", "wrong", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetPort(port)
+	err = c.Write(context.Background(), host, filepath.Join(t.TempDir(), "a"), strings.NewReader(""), sha256Hex(""))
+	if err == nil {
+		t.Error("expected an authentication error")
+	}
+}
+
+func TestNewInvalidKey(t *testing.T) {
+	if _, err := New("root", "", "not a key"); err == nil {
+		t.Error("expected an error for an invalid private key")
+	}
+}
@@ -0,0 +1,61 @@
+// Package retry retries proxmox operations that failed on a transient guest
+// lock, e.g. "can't lock file '/var/lock/qemu-server/lock-101.conf' - got
+// timeout" or "VM is locked (backup)".
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/apierr"
+)
+
+// DefaultTimeout bounds the retries when the context has no deadline.
+const DefaultTimeout = 5 * time.Minute
+
+// Variables so tests do not have to wait on real backoff
+var (
+	initialDelay = time.Second
+	maxDelay     = 16 * time.Second
+)
+
+// OnLock calls f until it succeeds, fails with an error that is not a lock
+// error, or the context deadline (or DefaultTimeout) is reached. The delay
+// between attempts doubles up to maxDelay.
+func OnLock(ctx context.Context, operation string, f func() error) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(DefaultTimeout)
+	}
+
+	delay := initialDelay
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || !apierr.Is(err, apierr.ErrLocked) {
+			return err
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return err
+		}
+
+		tflog.Warn(ctx, "Proxmox resource is locked, retrying", map[string]interface{}{
+			"operation": operation,
+			"attempt":   attempt,
+			"delay":     delay.String(),
+			"error":     err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
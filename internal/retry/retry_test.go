@@ -0,0 +1,114 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	errLocked   = errors.New("non 200: 500 can't lock file '/var/lock/qemu-server/lock-100.conf' - got timeout")
+	errNotFound = errors.New("non 200: 500 Configuration file 'nodes/pve/qemu-server/100.conf' does not exist")
+)
+
+func fastBackoff(t *testing.T) {
+	t.Helper()
+	initial, max := initialDelay, maxDelay
+	initialDelay, maxDelay = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() {
+		initialDelay, maxDelay = initial, max
+	})
+}
+
+// failing returns a func failing with errs in order and then succeeding
+func failing(errs ...error) (func() error, *int) {
+	calls := 0
+	return func() error {
+		calls++
+		if calls <= len(errs) {
+			return errs[calls-1]
+		}
+		return nil
+	}, &calls
+}
+
+func TestOnLock(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "success",
+			wantCalls: 1,
+		},
+		{
+			name:      "retries lock errors",
+			errs:      []error{errLocked, errLocked, errLocked},
+			wantCalls: 4,
+		},
+		{
+			name:      "returns other errors",
+			errs:      []error{errNotFound},
+			wantErr:   errNotFound,
+			wantCalls: 1,
+		},
+		{
+			name:      "returns other errors after a lock",
+			errs:      []error{errLocked, errNotFound},
+			wantErr:   errNotFound,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fastBackoff(t)
+			f, calls := failing(tt.errs...)
+			err := OnLock(context.Background(), "test", f)
+			if err != tt.wantErr {
+				t.Errorf("OnLock() = %v, want %v", err, tt.wantErr)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", *calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestOnLockDeadline(t *testing.T) {
+	fastBackoff(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := OnLock(ctx, "test", func() error {
+		calls++
+		return errLocked
+	})
+	if err != errLocked {
+		t.Errorf("OnLock() = %v, want the last lock error", err)
+	}
+	if calls < 2 {
+		t.Errorf("calls = %d, expected retries before the deadline", calls)
+	}
+}
+
+func TestOnLockCanceled(t *testing.T) {
+	fastBackoff(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := OnLock(ctx, "test", func() error {
+		calls++
+		cancel()
+		return errLocked
+	})
+	if err != errLocked {
+		t.Errorf("OnLock() = %v, want the lock error", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,117 @@
+package tasks
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/tasks"
+	"github.com/google/go-querystring/query"
+)
+
+// ReleaseTimeout bounds how long a slot is held for a single task, a task
+// still running after it no longer counts against the limit.
+const ReleaseTimeout = time.Hour
+
+// pollInterval is how often a running task is checked, a variable for tests
+var pollInterval = time.Second
+
+// Limiter wraps a proxmox client limiting how many tasks run at the same time
+// on each node. Calls that start a task wait for a free slot on the node and
+// the slot is held until the task finishes. Calls that can not return a UPID
+// are passed straight through.
+type Limiter struct {
+	c     tasks.HTTPClient
+	tasks *tasks.Client
+	max   int
+
+	mu    sync.Mutex
+	nodes map[string]chan struct{}
+}
+
+func NewLimiter(c tasks.HTTPClient, max int) *Limiter {
+	return &Limiter{
+		c:     c,
+		tasks: tasks.New(c),
+		max:   max,
+		nodes: make(map[string]chan struct{}),
+	}
+}
+
+func (l *Limiter) Do(ctx context.Context, route string, method string, response interface{}, request interface{}) error {
+	// Task starting calls are the only ones decoding into a string
+	upid, ok := response.(*string)
+	if !ok || method == http.MethodGet || !strings.HasPrefix(route, "/nodes/{node}/") {
+		return l.c.Do(ctx, route, method, response, request)
+	}
+	values, err := query.Values(request)
+	if err != nil || values.Get("node") == "" {
+		return l.c.Do(ctx, route, method, response, request)
+	}
+
+	done, err := l.Start(ctx, values.Get("node"))
+	if err != nil {
+		return err
+	}
+	err = l.c.Do(ctx, route, method, response, request)
+	done(*upid)
+	return err
+}
+
+// Start waits for a free slot on node for a task started outside of Do (i.e.
+// uploads). done must be called with the UPID of the started task, the slot
+// is held until the task finishes. Passing anything but a UPID releases the
+// slot immediately.
+func (l *Limiter) Start(ctx context.Context, node string) (done func(upid string), err error) {
+	slots := l.slots(node)
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return func(upid string) {
+		if !strings.HasPrefix(upid, "UPID:") {
+			<-slots
+			return
+		}
+		go l.release(slots, upid, node)
+	}, nil
+}
+
+func (l *Limiter) slots(node string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slots, ok := l.nodes[node]
+	if !ok {
+		slots = make(chan struct{}, l.max)
+		l.nodes[node] = slots
+	}
+	return slots
+}
+
+// release frees the node slot once the task is no longer running or after
+// ReleaseTimeout.
+func (l *Limiter) release(slots chan struct{}, upid string, node string) {
+	defer func() { <-slots }()
+	ctx, cancel := context.WithTimeout(context.Background(), ReleaseTimeout)
+	defer cancel()
+
+	req := tasks.ReadTaskStatusRequest{
+		Node: node,
+		Upid: upid,
+	}
+	for {
+		resp, err := l.tasks.ReadTaskStatus(ctx, req)
+		if err != nil || resp.Status != "running" {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package tasks
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/qemu"
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/tasks"
+)
+
+// fakeClient starts a task for every call decoding into a string, tasks run
+// until finish is called.
+type fakeClient struct {
+	mu       sync.Mutex
+	started  int
+	finished bool
+}
+
+func (f *fakeClient) Do(ctx context.Context, route string, method string, response interface{}, request interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch resp := response.(type) {
+	case *string:
+		f.started++
+		*resp = "UPID:pve:00001234:00005678:65000000:qmstart:100:root@pam:"
+	case *tasks.ReadTaskStatusResponse:
+		resp.Status = "running"
+		if f.finished {
+			resp.Status = "stopped"
+		}
+	}
+	return nil
+}
+
+func (f *fakeClient) finish() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.finished = true
+}
+
+func withFastPolling(t *testing.T) {
+	t.Helper()
+	interval := pollInterval
+	pollInterval = time.Millisecond
+	t.Cleanup(func() {
+		pollInterval = interval
+	})
+}
+
+func startTask(ctx context.Context, l *Limiter) error {
+	_, err := qemu.New(l).Create(ctx, qemu.CreateRequest{Node: "pve", Vmid: 100})
+	return err
+}
+
+func TestLimiterHoldsSlotUntilTaskFinishes(t *testing.T) {
+	withFastPolling(t)
+	c := &fakeClient{}
+	l := NewLimiter(c, 1)
+
+	if err := startTask(context.Background(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := startTask(ctx, l); err != context.DeadlineExceeded {
+		t.Fatalf("second task should wait for a slot, got %v", err)
+	}
+
+	c.finish()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := startTask(ctx, l); err != nil {
+		t.Fatalf("slot should be released once the task finished, got %v", err)
+	}
+}
+
+func TestLimiterPassesThroughCallsWithoutTasks(t *testing.T) {
+	withFastPolling(t)
+	c := &fakeClient{}
+	l := NewLimiter(c, 1)
+
+	if err := startTask(context.Background(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	// Node is full, calls that can not start a task must not wait
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := qemu.New(l).VmConfig(ctx, qemu.VmConfigRequest{Node: "pve", Vmid: 100}); err != nil {
+		t.Errorf("read should not wait for a slot, got %v", err)
+	}
+	err := qemu.New(l).UpdateVmConfig(ctx, qemu.UpdateVmConfigRequest{Node: "pve", Vmid: 100})
+	if err != nil {
+		t.Errorf("config update should not wait for a slot, got %v", err)
+	}
+}
+
+func TestLimiterStartReleasesWithoutUPID(t *testing.T) {
+	l := NewLimiter(&fakeClient{}, 1)
+
+	done, err := l.Start(context.Background(), "pve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done("")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Start(ctx, "pve"); err != nil {
+		t.Errorf("slot should be released when no task was started, got %v", err)
+	}
+}
@@ -2,10 +2,13 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/tasks"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/apierr"
 )
 
 type Client struct {
@@ -18,8 +21,47 @@ func New(p tasks.HTTPClient) *Client {
 	}
 }
 
+// ExitError is returned when a task finished with an exit status other than OK.
+type ExitError struct {
+	UPID       string
+	Node       string
+	ExitStatus string
+}
+
+func (e *ExitError) Error() string {
+	return "received bad exit status: " + e.ExitStatus
+}
+
+// Is matches the exit status against the apierr kinds so failed tasks can be
+// handled like failed requests.
+func (e *ExitError) Is(target error) bool {
+	kind := apierr.Kind(0, e.ExitStatus)
+	return kind != nil && errors.Is(kind, target)
+}
+
 func (t *Client) Wait(ctx context.Context, upid string, node string) diag.Diagnostics {
 	diag := diag.Diagnostics{}
+	err := t.WaitForExit(ctx, upid, node)
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		diag.AddError(
+			"Error waiting for VM to be created",
+			"An unexpected error occurred when getting the task. "+
+				"Proxmox Task Error: "+err.Error(),
+		)
+	} else if err != nil {
+		diag.AddError(
+			fmt.Sprintf("Error waiting for task id %s on %s", upid, node),
+			"An unexpected error occurred when getting the task. "+
+				"Proxmox Task Error: "+err.Error(),
+		)
+	}
+	return diag
+}
+
+// WaitForExit blocks until the task finishes, returning an *ExitError if it
+// did not exit successfully.
+func (t *Client) WaitForExit(ctx context.Context, upid string, node string) error {
 	req := tasks.ReadTaskStatusRequest{
 		Node: node,
 		Upid: upid,
@@ -28,12 +70,7 @@ func (t *Client) Wait(ctx context.Context, upid string, node string) diag.Diagno
 	for {
 		resp, err := t.c.ReadTaskStatus(ctx, req)
 		if err != nil {
-			diag.AddError(
-				fmt.Sprintf("Error waiting for task id %s on %s", upid, node),
-				"An unexpected error occurred when getting the task. "+
-					"Proxmox Task Error: "+err.Error(),
-			)
-			return diag
+			return err
 		}
 		if resp.Status != "running" {
 			exit = *resp.Exitstatus
@@ -41,12 +78,11 @@ func (t *Client) Wait(ctx context.Context, upid string, node string) diag.Diagno
 		}
 	}
 	if exit != "OK" {
-		diag.AddError(
-			"Error waiting for VM to be created",
-			"An unexpected error occurred when getting the task. "+
-				"Proxmox Task Error: received bad exit status: "+exit,
-		)
-		return diag
+		return &ExitError{
+			UPID:       upid,
+			Node:       node,
+			ExitStatus: exit,
+		}
 	}
-	return diag
+	return nil
 }
@@ -0,0 +1,176 @@
+// Package upload streams local files to a node storage. The generated API
+// client only sends form encoded requests, the upload endpoint expects a
+// multipart body.
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+type Client struct {
+	client   *http.Client
+	baseAddr string
+	cookie   string
+	csrf     string
+	limiter  Limiter
+}
+
+// Limiter limits the tasks running on a node, uploads count as a task from
+// the start of the transfer until proxmox moved the file into place.
+type Limiter interface {
+	Start(ctx context.Context, node string) (done func(upid string), err error)
+}
+
+func New(baseAddr string, cookie string, csrf string) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return &Client{
+		client:   &http.Client{Transport: transport},
+		baseAddr: baseAddr,
+		cookie:   cookie,
+		csrf:     csrf,
+	}
+}
+
+// SetLimiter makes uploads wait for a free task slot on the node.
+func (c *Client) SetLimiter(l Limiter) {
+	c.limiter = l
+}
+
+type Request struct {
+	Node     string
+	Storage  string
+	Content  string
+	Filename string
+
+	// Optional, proxmox verifies the uploaded file when set
+	Checksum          string
+	ChecksumAlgorithm string
+}
+
+// File uploads the file at path and returns the UPID of the task moving it
+// into place. The file is streamed, it is never loaded into memory.
+func (c *Client) File(ctx context.Context, req Request, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	return c.Upload(ctx, req, f, info.Size())
+}
+
+// Upload streams size bytes of r as the file content and returns the UPID of
+// the task moving it into place.
+func (c *Client) Upload(ctx context.Context, req Request, r io.Reader, size int64) (upid string, err error) {
+	if c.limiter != nil {
+		done, err := c.limiter.Start(ctx, req.Node)
+		if err != nil {
+			return "", err
+		}
+		defer func() { done(upid) }()
+	}
+
+	// pveproxy does not accept chunked uploads, the body is sent with a
+	// known length: the form up to the file header, the file and the closing
+	// boundary.
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	if err := writeHead(mw, req); err != nil {
+		return "", err
+	}
+	headLen := form.Len()
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	head := bytes.NewReader(form.Bytes()[:headLen])
+	tail := bytes.NewReader(form.Bytes()[headLen:])
+	body := io.MultiReader(head, io.LimitReader(r, size), tail)
+
+	route := fmt.Sprintf("%s/nodes/%s/storage/%s/upload",
+		c.baseAddr, url.PathEscape(req.Node), url.PathEscape(req.Storage))
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, route, body)
+	if err != nil {
+		return "", err
+	}
+	hreq.ContentLength = int64(form.Len()) + size
+	hreq.Header.Set("Content-Type", mw.FormDataContentType())
+	if c.cookie != "" {
+		hreq.Header.Add("Authorization", fmt.Sprintf("PVEAuthCookie=%s", c.cookie))
+	}
+	if c.csrf != "" {
+		hreq.Header.Add("CSRFPreventionToken", c.csrf)
+	}
+
+	resp, err := c.client.Do(hreq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		return "", fmt.Errorf("parameter error: %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("non 200: %s", resp.Status)
+	}
+
+	var data struct {
+		Data string `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return "", err
+	}
+	return data.Data, nil
+}
+
+// writeHead writes the form fields followed by the file part header, proxmox
+// reads the fields while the file is still being received.
+func writeHead(mw *multipart.Writer, req Request) error {
+	fields := [][2]string{
+		{"content", req.Content},
+	}
+	if req.Checksum != "" {
+		fields = append(fields,
+			[2]string{"checksum", req.Checksum},
+			[2]string{"checksum-algorithm", req.ChecksumAlgorithm},
+		)
+	}
+	for _, field := range fields {
+		if err := mw.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	_, err := mw.CreateFormFile("filename", filepath.Base(req.Filename))
+	return err
+}
+
+// Sha256 returns the hex encoded sha256 sum of the file at path.
+func Sha256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,163 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testUPID = "UPID:pve:00001234:00005678:65000000:imgcopy::root@pam:"
+
+type received struct {
+	contentLength    int64
+	transferEncoding []string
+	fields           map[string]string
+	filename         string
+	file             string
+}
+
+func testServer(t *testing.T, got *received) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nodes/pve/storage/local/upload" {
+			http.NotFound(w, r)
+			return
+		}
+		got.contentLength = r.ContentLength
+		got.transferEncoding = r.TransferEncoding
+		got.fields = map[string]string{}
+
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, err := io.ReadAll(part)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if part.FormName() == "filename" {
+				got.filename = part.FileName()
+				got.file = string(data)
+				continue
+			}
+			got.fields[part.FormName()] = string(data)
+		}
+		fmt.Fprintf(w, `{"data":%q}`, testUPID)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFile(t *testing.T) {
+	var got received
+	srv := testServer(t, &got)
+
+	content := strings.Repeat("disk image ", 1024)
+	path := filepath.Join(t.TempDir(), "debian.qcow2")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(srv.URL, "ticket", "csrf")
+	upid, err := c.File(context.Background(), Request{
+		Node:              "pve",
+		Storage:           "local",
+		Content:           "import",
+		Filename:          "debian.qcow2",
+		Checksum:          "abc",
+		ChecksumAlgorithm: "sha256",
+	}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upid != testUPID {
+		t.Errorf("upid = %q, want %q", upid, testUPID)
+	}
+	if got.contentLength <= int64(len(content)) {
+		t.Errorf("content length = %d, expected the file size plus the form", got.contentLength)
+	}
+	if len(got.transferEncoding) != 0 {
+		t.Errorf("transfer encoding = %v, the body must not be chunked", got.transferEncoding)
+	}
+	if got.file != content {
+		t.Errorf("received %d bytes, want %d", len(got.file), len(content))
+	}
+	if got.filename != "debian.qcow2" {
+		t.Errorf("filename = %q", got.filename)
+	}
+	want := map[string]string{"content": "import", "checksum": "abc", "checksum-algorithm": "sha256"}
+	for k, v := range want {
+		if got.fields[k] != v {
+			t.Errorf("field %s = %q, want %q", k, got.fields[k], v)
+		}
+	}
+}
+
+func TestUploadShortReader(t *testing.T) {
+	var got received
+	srv := testServer(t, &got)
+
+	c := New(srv.URL, "", "")
+	_, err := c.Upload(context.Background(), Request{
+		Node:     "pve",
+		Storage:  "local",
+		Content:  "iso",
+		Filename: "a.iso",
+	}, strings.NewReader("short"), 100)
+	if err == nil {
+		t.Error("expected an error when the reader is shorter than size")
+	}
+}
+
+type fakeLimiter struct {
+	started []string
+	done    []string
+}
+
+func (l *fakeLimiter) Start(ctx context.Context, node string) (func(string), error) {
+	l.started = append(l.started, node)
+	return func(upid string) {
+		l.done = append(l.done, upid)
+	}, nil
+}
+
+func TestUploadLimiter(t *testing.T) {
+	var got received
+	srv := testServer(t, &got)
+
+	l := &fakeLimiter{}
+	c := New(srv.URL, "", "")
+	c.SetLimiter(l)
+	_, err := c.Upload(context.Background(), Request{
+		Node:     "pve",
+		Storage:  "local",
+		Content:  "iso",
+		Filename: "a.iso",
+	}, strings.NewReader("iso"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.started) != 1 || l.started[0] != "pve" {
+		t.Errorf("started = %v, want one slot on pve", l.started)
+	}
+	if len(l.done) != 1 || l.done[0] != testUPID {
+		t.Errorf("done = %v, want the upload task", l.done)
+	}
+}
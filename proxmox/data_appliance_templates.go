@@ -0,0 +1,171 @@
+package proxmox
+
+import (
+	"context"
+	"sort"
+
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type applianceTemplateModel struct {
+	Template          types.String `tfsdk:"template"`
+	Package           types.String `tfsdk:"package"`
+	Version           types.String `tfsdk:"version"`
+	OS                types.String `tfsdk:"os"`
+	Section           types.String `tfsdk:"section"`
+	Headline          types.String `tfsdk:"headline"`
+	Location          types.String `tfsdk:"location"`
+	Checksum          types.String `tfsdk:"checksum"`
+	ChecksumAlgorithm types.String `tfsdk:"checksum_algorithm"`
+}
+
+type applianceTemplatesModel struct {
+	Node      types.String              `tfsdk:"node"`
+	Section   types.String              `tfsdk:"section"`
+	Templates []*applianceTemplateModel `tfsdk:"templates"`
+}
+
+type dataApplianceTemplates struct {
+	n *nodes.Client
+}
+
+func (d *dataApplianceTemplates) SetClient(p apiClient) {
+	d.n = nodes.New(p)
+}
+
+// Metadata returns the data source type name.
+func (d *dataApplianceTemplates) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_appliance_templates"
+}
+
+// Schema defines the schema for the data source.
+func (d *dataApplianceTemplates) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The container templates available from a nodes appliance index (pveam)",
+		Attributes: map[string]schema.Attribute{
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the node to read the appliance index from",
+			},
+			"section": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list templates in this section (i.e. system, turnkeylinux)",
+			},
+			"templates": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The available templates",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"template": schema.StringAttribute{
+							Computed:    true,
+							Description: "The template name, usable as a vztmpl appliance",
+						},
+						"package": schema.StringAttribute{
+							Computed:    true,
+							Description: "The package name",
+						},
+						"version": schema.StringAttribute{
+							Computed:    true,
+							Description: "The template version",
+						},
+						"os": schema.StringAttribute{
+							Computed:    true,
+							Description: "The operating system of the template",
+						},
+						"section": schema.StringAttribute{
+							Computed:    true,
+							Description: "The section of the template",
+						},
+						"headline": schema.StringAttribute{
+							Computed:    true,
+							Description: "A short description of the template",
+						},
+						"location": schema.StringAttribute{
+							Computed:    true,
+							Description: "The url the template is downloaded from",
+						},
+						"checksum": schema.StringAttribute{
+							Computed:    true,
+							Description: "The checksum of the template",
+						},
+						"checksum_algorithm": schema.StringAttribute{
+							Computed:    true,
+							Description: "The algorithm of the checksum (sha512 or md5)",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *dataApplianceTemplates) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state applianceTemplatesModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	appliances, err := d.n.Aplinfo(ctx, nodes.AplinfoRequest{
+		Node: state.Node.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to get appliance templates",
+			"An unexpected error occurred when trying to retreive the "+
+				"node appliance index. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+
+	state.Templates = applianceTemplates(appliances, state.Section)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// applianceTemplates converts the aplinfo index into templates sorted by name,
+// only keeping the given section unless it is null.
+func applianceTemplates(appliances []map[string]interface{}, section types.String) []*applianceTemplateModel {
+	str := func(appliance map[string]interface{}, key string) string {
+		if v, ok := appliance[key].(string); ok {
+			return v
+		}
+		return ""
+	}
+	templates := make([]*applianceTemplateModel, 0, len(appliances))
+	for _, appliance := range appliances {
+		if !section.IsNull() && str(appliance, "section") != section.ValueString() {
+			continue
+		}
+		template := &applianceTemplateModel{
+			Template: types.StringValue(str(appliance, "template")),
+			Package:  types.StringValue(str(appliance, "package")),
+			Version:  types.StringValue(str(appliance, "version")),
+			OS:       types.StringValue(str(appliance, "os")),
+			Section:  types.StringValue(str(appliance, "section")),
+			Headline: types.StringValue(str(appliance, "headline")),
+			Location: types.StringValue(str(appliance, "location")),
+		}
+		if sum := str(appliance, "sha512sum"); sum != "" {
+			template.Checksum = types.StringValue(sum)
+			template.ChecksumAlgorithm = types.StringValue("sha512")
+		} else if sum := str(appliance, "md5sum"); sum != "" {
+			template.Checksum = types.StringValue(sum)
+			template.ChecksumAlgorithm = types.StringValue("md5")
+		} else {
+			template.Checksum = types.StringNull()
+			template.ChecksumAlgorithm = types.StringNull()
+		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Template.ValueString() < templates[j].Template.ValueString()
+	})
+	return templates
+}
@@ -0,0 +1,89 @@
+package proxmox
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestApplianceTemplates(t *testing.T) {
+	appliances := []map[string]interface{}{
+		{
+			"template":  "debian-12-standard_12.2-1_amd64.tar.zst",
+			"package":   "debian-12-standard",
+			"version":   "12.2-1",
+			"os":        "debian-12",
+			"section":   "system",
+			"location":  "http://download.proxmox.com/images/system/debian-12-standard_12.2-1_amd64.tar.zst",
+			"sha512sum": "sha512-of-debian",
+			"md5sum":    "md5-of-debian",
+		},
+		{
+			"template": "alpine-3.18-default_20230607_amd64.tar.xz",
+			"section":  "system",
+			"md5sum":   "md5-of-alpine",
+		},
+		{
+			"template": "debian-11-turnkey-nextcloud_17.2-1_amd64.tar.gz",
+			"section":  "turnkeylinux",
+		},
+		{
+			// Unexpected types are treated as missing
+			"template": "broken.tar.gz",
+			"section":  "mail",
+			"version":  12,
+		},
+	}
+
+	all := applianceTemplates(appliances, types.StringNull())
+	names := make([]string, len(all))
+	for i, template := range all {
+		names[i] = template.Template.ValueString()
+	}
+	wantNames := []string{
+		"alpine-3.18-default_20230607_amd64.tar.xz",
+		"broken.tar.gz",
+		"debian-11-turnkey-nextcloud_17.2-1_amd64.tar.gz",
+		"debian-12-standard_12.2-1_amd64.tar.zst",
+	}
+	if len(names) != len(wantNames) {
+		t.Fatalf("templates = %v, want %v", names, wantNames)
+	}
+	for i := range wantNames {
+		if names[i] != wantNames[i] {
+			t.Errorf("templates = %v, want sorted %v", names, wantNames)
+			break
+		}
+	}
+
+	checksums := map[string][2]types.String{
+		"alpine-3.18-default_20230607_amd64.tar.xz": {types.StringValue("md5-of-alpine"), types.StringValue("md5")},
+		"broken.tar.gz": {types.StringNull(), types.StringNull()},
+		"debian-11-turnkey-nextcloud_17.2-1_amd64.tar.gz": {types.StringNull(), types.StringNull()},
+		"debian-12-standard_12.2-1_amd64.tar.zst":         {types.StringValue("sha512-of-debian"), types.StringValue("sha512")},
+	}
+	for _, template := range all {
+		want := checksums[template.Template.ValueString()]
+		if !template.Checksum.Equal(want[0]) || !template.ChecksumAlgorithm.Equal(want[1]) {
+			t.Errorf("%s checksum = %v %v, want %v %v", template.Template.ValueString(),
+				template.Checksum, template.ChecksumAlgorithm, want[0], want[1])
+		}
+		if template.Template.ValueString() == "broken.tar.gz" && template.Version.ValueString() != "" {
+			t.Errorf("version = %v, want empty", template.Version)
+		}
+	}
+
+	system := applianceTemplates(appliances, types.StringValue("system"))
+	if len(system) != 2 {
+		t.Fatalf("system templates = %d, want 2", len(system))
+	}
+	for _, template := range system {
+		if template.Section.ValueString() != "system" {
+			t.Errorf("%s section = %v, want system", template.Template.ValueString(), template.Section)
+		}
+	}
+
+	if none := applianceTemplates(appliances, types.StringValue("unknown")); len(none) != 0 {
+		t.Errorf("templates = %d, want none for an unknown section", len(none))
+	}
+}
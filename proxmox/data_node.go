@@ -3,7 +3,6 @@ package proxmox
 import (
 	"context"
 
-	"github.com/FreekingDean/proxmox-api-go/proxmox"
 	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/network"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -20,7 +19,7 @@ type dataNode struct {
 	net *network.Client
 }
 
-func (d *dataNode) SetClient(p *proxmox.Client) {
+func (d *dataNode) SetClient(p apiClient) {
 	d.net = network.New(p)
 }
 
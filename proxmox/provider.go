@@ -4,15 +4,21 @@ import (
 	"context"
 	"os"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/FreekingDean/proxmox-api-go/proxmox"
 	"github.com/FreekingDean/proxmox-api-go/proxmox/access"
+
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/remote"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/tasks"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/upload"
 )
 
 // Ensure the implementation satisfies the expected interfaces
@@ -27,7 +33,14 @@ func New() provider.Provider {
 
 // proxmoxProvider is the provider implementation.
 type proxmoxProvider struct {
-	client *proxmox.Client
+	client   apiClient
+	uploader *upload.Client
+	remote   *remote.Client
+}
+
+// apiClient is the proxmox http client shared by all resources.
+type apiClient interface {
+	Do(ctx context.Context, route string, method string, response interface{}, request interface{}) error
 }
 
 // Metadata returns the provider type name.
@@ -53,6 +66,34 @@ func (p *proxmoxProvider) Schema(_ context.Context, _ provider.SchemaRequest, re
 				Sensitive:   true,
 				Description: "The password of the user attempting to connect.",
 			},
+			"max_concurrent_tasks_per_node": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The maximum number of tasks (create, clone, update, ...) started concurrently on a single node, further tasks queue until one finishes. (default: 0, unlimited)",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"ssh_username": schema.StringAttribute{
+				Optional:    true,
+				Description: "The user connecting to the nodes over SSH to write snippets, it needs write access to the storage directories. (default: root)",
+			},
+			"ssh_password": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The password of the SSH user.",
+			},
+			"ssh_private_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "A PEM encoded private key of the SSH user, keys of a running ssh-agent are used as well.",
+			},
+			"ssh_port": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The port sshd listens on on every node. (default: 22)",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
 		},
 	}
 }
@@ -61,6 +102,13 @@ type proxmoxProviderModel struct {
 	Host     types.String `tfsdk:"host"`
 	Username types.String `tfsdk:"username"`
 	Password types.String `tfsdk:"password"`
+
+	MaxConcurrentTasksPerNode types.Int64 `tfsdk:"max_concurrent_tasks_per_node"`
+
+	SSHUsername   types.String `tfsdk:"ssh_username"`
+	SSHPassword   types.String `tfsdk:"ssh_password"`
+	SSHPrivateKey types.String `tfsdk:"ssh_private_key"`
+	SSHPort       types.Int64  `tfsdk:"ssh_port"`
 }
 
 // Configure prepares a Proxmox API client for data sources and resources.
@@ -139,16 +187,56 @@ func (p *proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureR
 	client.SetCookie(*ticket.Ticket)
 	client.SetCsrf(*ticket.Csrfpreventiontoken)
 	p.client = client
+	p.uploader = upload.New(host, *ticket.Ticket, *ticket.Csrfpreventiontoken)
+	if config.MaxConcurrentTasksPerNode.ValueInt64() > 0 {
+		limiter := tasks.NewLimiter(client, int(config.MaxConcurrentTasksPerNode.ValueInt64()))
+		p.client = limiter
+		p.uploader.SetLimiter(limiter)
+	}
+
+	// Snippets can only be written over SSH, the client is left unset
+	// without any credentials to use
+	sshUsername := "root"
+	if v := os.Getenv("PROXMOX_SSH_USERNAME"); v != "" {
+		sshUsername = v
+	}
+	sshPassword := os.Getenv("PROXMOX_SSH_PASSWORD")
+	sshPrivateKey := os.Getenv("PROXMOX_SSH_PRIVATE_KEY")
+	if !config.SSHUsername.IsNull() {
+		sshUsername = config.SSHUsername.ValueString()
+	}
+	if !config.SSHPassword.IsNull() {
+		sshPassword = config.SSHPassword.ValueString()
+	}
+	if !config.SSHPrivateKey.IsNull() {
+		sshPrivateKey = config.SSHPrivateKey.ValueString()
+	}
+	if sshPassword != "" || sshPrivateKey != "" || os.Getenv("SSH_AUTH_SOCK") != "" {
+		p.remote, err = remote.New(sshUsername, sshPassword, sshPrivateKey)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ssh_private_key"),
+				"Invalid SSH Private Key",
+				"The provider cannot create the SSH client as the private key could not be parsed. "+
+					"Error: "+err.Error(),
+			)
+			return
+		}
+		if config.SSHPort.ValueInt64() > 0 {
+			p.remote.SetPort(int(config.SSHPort.ValueInt64()))
+		}
+	}
 
 	// Make the Proxmox client available during DataSource and Resource
 	// type Configure methods.
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	resp.DataSourceData = p.client
+	resp.ResourceData = p.client
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *proxmoxProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
+		p.dataFunc(&dataApplianceTemplates{}),
 		p.dataFunc(&dataNode{}),
 	}
 }
@@ -157,26 +245,45 @@ func (p *proxmoxProvider) DataSources(_ context.Context) []func() datasource.Dat
 func (p *proxmoxProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		p.resourceFunc(&resourceNodeStorageContent{}),
+		p.resourceFunc(&resourceNodeContainer{}),
 		p.resourceFunc(&resourceNodeVirtualMachine{}),
+		p.resourceFunc(&resourceNodeVirtualMachineSnapshot{}),
 		p.resourceFunc(&resourceClusterHAResource{}),
 	}
 }
 
 type clientResource interface {
 	resource.Resource
-	SetClient(c *proxmox.Client)
+	SetClient(c apiClient)
 }
 
 func (p *proxmoxProvider) resourceFunc(r clientResource) func() resource.Resource {
 	return func() resource.Resource {
 		r.SetClient(p.client)
+		if u, ok := r.(uploadResource); ok {
+			u.SetUploader(p.uploader)
+		}
+		if rr, ok := r.(remoteResource); ok {
+			rr.SetRemote(p.remote)
+		}
 		return r
 	}
 }
 
+// uploadResource is implemented by resources sending local files to proxmox.
+type uploadResource interface {
+	SetUploader(u *upload.Client)
+}
+
+// remoteResource is implemented by resources writing files onto the nodes
+// over SSH.
+type remoteResource interface {
+	SetRemote(r *remote.Client)
+}
+
 type clientDataSource interface {
 	datasource.DataSource
-	SetClient(c *proxmox.Client)
+	SetClient(c apiClient)
 }
 
 func (p *proxmoxProvider) dataFunc(d clientDataSource) func() datasource.DataSource {
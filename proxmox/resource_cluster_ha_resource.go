@@ -2,8 +2,6 @@ package proxmox
 
 import (
 	"context"
-	"fmt"
-	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -14,6 +12,8 @@ import (
 
 	"github.com/FreekingDean/proxmox-api-go/proxmox"
 	"github.com/FreekingDean/proxmox-api-go/proxmox/cluster/ha/resources"
+
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/apierr"
 )
 
 type resourceClusterHAResourceModel struct {
@@ -29,7 +29,7 @@ type resourceClusterHAResource struct {
 	r *resources.Client
 }
 
-func (r *resourceClusterHAResource) SetClient(p *proxmox.Client) {
+func (r *resourceClusterHAResource) SetClient(p apiClient) {
 	r.r = resources.New(p)
 }
 
@@ -164,6 +164,10 @@ func (r *resourceClusterHAResource) Read(ctx context.Context, req resource.ReadR
 	res, err := r.r.Find(ctx, resources.FindRequest{
 		Sid: state.ID.ValueString(),
 	})
+	if apierr.Is(err, apierr.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error reading HA resource",
@@ -199,7 +203,7 @@ func (r *resourceClusterHAResource) ImportState(ctx context.Context, req resourc
 	res, err := r.r.Find(ctx, resources.FindRequest{
 		Sid: req.ID,
 	})
-	if strings.Contains(err.Error(), fmt.Sprintf("no such resource '%s'", req.ID)) {
+	if apierr.Is(err, apierr.ErrNotFound) {
 		resp.State.RemoveResource(ctx)
 		return
 	}
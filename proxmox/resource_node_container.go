@@ -0,0 +1,1359 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/FreekingDean/proxmox-api-go/proxmox"
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/lxc"
+
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/apierr"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/retry"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/tasks"
+)
+
+const (
+	defaultContainerMemory = 512
+	defaultContainerSwap   = 512
+)
+
+var (
+	macAddressRegex = regexp.MustCompile(`^[0-9a-fA-F]{2}(:[0-9a-fA-F]{2}){5}$`)
+	hostPathRegex   = regexp.MustCompile(`^/`)
+)
+
+type RootFS struct {
+	VolumeID types.String `tfsdk:"volume_id"`
+	Storage  types.String `tfsdk:"storage"`
+	SizeGB   types.Int64  `tfsdk:"size_gb"`
+}
+
+type MountPoint struct {
+	Path      types.String `tfsdk:"path"`
+	VolumeID  types.String `tfsdk:"volume_id"`
+	Storage   types.String `tfsdk:"storage"`
+	SizeGB    types.Int64  `tfsdk:"size_gb"`
+	HostPath  types.String `tfsdk:"host_path"`
+	Backup    types.Bool   `tfsdk:"backup"`
+	ACL       types.Bool   `tfsdk:"acl"`
+	Quota     types.Bool   `tfsdk:"quota"`
+	Replicate types.Bool   `tfsdk:"replicate"`
+	Readonly  types.Bool   `tfsdk:"readonly"`
+}
+
+type ContainerFeatures struct {
+	Nesting    types.Bool     `tfsdk:"nesting"`
+	Keyctl     types.Bool     `tfsdk:"keyctl"`
+	Fuse       types.Bool     `tfsdk:"fuse"`
+	Mknod      types.Bool     `tfsdk:"mknod"`
+	ForceRwSys types.Bool     `tfsdk:"force_rw_sys"`
+	Mount      []types.String `tfsdk:"mount"`
+}
+
+type LXCConfig struct {
+	Key   types.String `tfsdk:"key"`
+	Value types.String `tfsdk:"value"`
+}
+
+type ContainerNetwork struct {
+	Name       types.String `tfsdk:"name"`
+	Bridge     types.String `tfsdk:"bridge"`
+	IP         types.String `tfsdk:"ip"`
+	Gateway    types.String `tfsdk:"gateway"`
+	IP6        types.String `tfsdk:"ip6"`
+	Gateway6   types.String `tfsdk:"gateway6"`
+	VLAN       types.Int64  `tfsdk:"vlan"`
+	Firewall   types.Bool   `tfsdk:"firewall"`
+	MACAddress types.String `tfsdk:"mac_address"`
+}
+
+type resourceNodeContainerModel struct {
+	ID            types.Int64         `tfsdk:"id"`
+	Node          types.String        `tfsdk:"node"`
+	OSTemplate    types.String        `tfsdk:"ostemplate"`
+	Hostname      types.String        `tfsdk:"hostname"`
+	Cores         types.Int64         `tfsdk:"cores"`
+	Memory        types.Int64         `tfsdk:"memory"`
+	Swap          types.Int64         `tfsdk:"swap"`
+	RootFS        *RootFS             `tfsdk:"rootfs"`
+	MountPoints   []*MountPoint       `tfsdk:"mount_point"`
+	Unprivileged  types.Bool          `tfsdk:"unprivileged"`
+	Features      *ContainerFeatures  `tfsdk:"features"`
+	LXCConfig     []*LXCConfig        `tfsdk:"lxc_config"`
+	Password      types.String        `tfsdk:"password"`
+	SSHPublicKeys []types.String      `tfsdk:"ssh_public_keys"`
+	Networks      []*ContainerNetwork `tfsdk:"network"`
+	StartOnCreate types.Bool          `tfsdk:"start_on_create"`
+}
+
+type resourceNodeContainer struct {
+	t *tasks.Client
+	l *lxc.Client
+}
+
+func (r *resourceNodeContainer) SetClient(p apiClient) {
+	r.t = tasks.New(p)
+	r.l = lxc.New(p)
+}
+
+func (r *resourceNodeContainer) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_node_container"
+}
+
+func (r *resourceNodeContainer) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A LXC container",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.Int64Attribute{
+				Required:    true,
+				Description: "The vmid of the container",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the node to schedule the container onto",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ostemplate": schema.StringAttribute{
+				Required:    true,
+				Description: "The volid of the OS template to create the container from (i.e. local:vztmpl/debian-12-standard_12.2-1_amd64.tar.zst), changing it replaces the container unless it was imported",
+				PlanModifiers: []planmodifier.String{
+					requiresReplaceUnlessImportedString(),
+				},
+			},
+			"hostname": schema.StringAttribute{
+				Optional:    true,
+				Description: "The hostname of the container (default: CT<id>)",
+			},
+			"cores": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The number of cores assigned to the container (default: all host cores)",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 8192),
+				},
+			},
+			"memory": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Memory allocation in MB (default: 512)",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(16),
+				},
+			},
+			"swap": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Swap allocation in MB (default: 512)",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"unprivileged": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Run the container as an unprivileged user (default: false)",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"password": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The root password inside the container, only set on creation, changing it replaces the container unless it was imported",
+				PlanModifiers: []planmodifier.String{
+					requiresReplaceUnlessImportedString(),
+				},
+			},
+			"ssh_public_keys": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Public SSH keys for the root user (OpenSSH format), only set on creation, changing them replaces the container unless it was imported",
+				PlanModifiers: []planmodifier.List{
+					requiresReplaceUnlessImportedList(),
+				},
+			},
+			"lxc_config": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The raw lxc.* entries of the container config (i.e. lxc.idmap). These are read only, the proxmox API rejects lxc.* keys so they can only be changed on the host in /etc/pve/lxc/<id>.conf",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							Computed:    true,
+							Description: "The lxc config key",
+						},
+						"value": schema.StringAttribute{
+							Computed:    true,
+							Description: "The lxc config value",
+						},
+					},
+				},
+			},
+			"start_on_create": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Start the container once it has been created",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"rootfs": schema.SingleNestedBlock{
+				Description: "The root filesystem of the container",
+				Attributes: map[string]schema.Attribute{
+					"volume_id": schema.StringAttribute{
+						Computed:    true,
+						Description: "The volume ID of the root filesystem",
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
+					},
+					"storage": schema.StringAttribute{
+						Optional:    true,
+						Description: "The node storage ID to place the root filesystem",
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
+					},
+					"size_gb": schema.Int64Attribute{
+						Optional:    true,
+						Description: "The size in GB of the root filesystem, can only be grown in place",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+				},
+			},
+			"features": schema.SingleNestedBlock{
+				Description: "Advanced container features, anything but nesting requires root@pam. Proxmox has no features limited to privileged containers, keyctl, mknod and force_rw_sys only apply to unprivileged containers and are rejected on privileged ones",
+				Attributes: map[string]schema.Attribute{
+					"nesting": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Allow nesting, i.e. running docker inside the container (default: false)",
+					},
+					"keyctl": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Allow the use of the keyctl() system call, unprivileged containers only (default: false)",
+					},
+					"fuse": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Allow using fuse file systems (default: false)",
+					},
+					"mknod": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Allow using mknod() to add certain device nodes, unprivileged containers only (default: false)",
+					},
+					"force_rw_sys": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Mount /sys as rw instead of mixed, unprivileged containers only (default: false)",
+					},
+					"mount": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+						Description: "File system types the container is allowed to mount (i.e. nfs, cifs)",
+					},
+				},
+			},
+			"mount_point": schema.ListNestedBlock{
+				Description: "A storage backed volume or host directory mounted into the container. Removing a mount point destroys its volume, replacing a volume with a host_path keeps it as an unused volume",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							Required:    true,
+							Description: "The path of the mount point inside the container (i.e. /srv/data)",
+						},
+						"volume_id": schema.StringAttribute{
+							Computed:    true,
+							Description: "The volume ID (or host path) of the mount point",
+							PlanModifiers: []planmodifier.String{
+								stringplanmodifier.UseStateForUnknown(),
+							},
+						},
+						"storage": schema.StringAttribute{
+							Optional:    true,
+							Description: "The node storage ID to allocate the volume on, changing it moves the volume (the container must be stopped)",
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("host_path")),
+								stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("size_gb")),
+							},
+						},
+						"size_gb": schema.Int64Attribute{
+							Optional:    true,
+							Description: "The size in GB of the volume, can only be grown in place",
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+								int64validator.AlsoRequires(path.MatchRelative().AtParent().AtName("storage")),
+							},
+						},
+						"host_path": schema.StringAttribute{
+							Optional:    true,
+							Description: "A directory on the host to bind mount into the container (requires root@pam)",
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(hostPathRegex, "must be an absolute path"),
+							},
+						},
+						"backup": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Include the mount point in backups (default: false)",
+						},
+						"acl": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Explicitly enable or disable ACL support",
+						},
+						"quota": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Enable user quotas inside the container (default: false)",
+						},
+						"replicate": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Include the volume in storage replica jobs (default: true)",
+						},
+						"readonly": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Mount the volume read-only (default: false)",
+						},
+					},
+				},
+			},
+			"network": schema.ListNestedBlock{
+				Description: "A network interface",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "The interface name inside the container (i.e. eth0)",
+						},
+						"bridge": schema.StringAttribute{
+							Required:    true,
+							Description: "The hosts network bridge to use",
+						},
+						"ip": schema.StringAttribute{
+							Optional:    true,
+							Description: "The IPv4 address in CIDR format, dhcp or manual",
+						},
+						"gateway": schema.StringAttribute{
+							Optional:    true,
+							Description: "The IPv4 default gateway",
+						},
+						"ip6": schema.StringAttribute{
+							Optional:    true,
+							Description: "The IPv6 address in CIDR format, auto, dhcp or manual",
+						},
+						"gateway6": schema.StringAttribute{
+							Optional:    true,
+							Description: "The IPv6 default gateway",
+						},
+						"vlan": schema.Int64Attribute{
+							Optional:    true,
+							Description: "The VLAN tag of the interface",
+							Validators: []validator.Int64{
+								int64validator.Between(1, 4094),
+							},
+						},
+						"firewall": schema.BoolAttribute{
+							Optional:    true,
+							Description: "If set will utilize the proxmox firewall",
+						},
+						"mac_address": schema.StringAttribute{
+							Optional:    true,
+							Computed:    true,
+							Description: "The MAC address of the interface, generated by proxmox if unset",
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(macAddressRegex, "must be a MAC address (XX:XX:XX:XX:XX:XX)"),
+							},
+							PlanModifiers: []planmodifier.String{
+								stringplanmodifier.UseStateForUnknown(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *resourceNodeContainer) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var unprivileged types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("unprivileged"), &unprivileged)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !unprivileged.IsUnknown() && !unprivileged.ValueBool() {
+		// These features only apply to the user namespace of unprivileged containers
+		for _, feature := range []string{"keyctl", "mknod", "force_rw_sys"} {
+			var enabled types.Bool
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("features").AtName(feature), &enabled)...)
+			if enabled.ValueBool() {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("features").AtName(feature),
+					"Invalid container feature",
+					fmt.Sprintf("The %s feature can only be enabled on unprivileged containers.", feature),
+				)
+			}
+		}
+	}
+
+	var rootfs types.Object
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rootfs"), &rootfs)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if rootfs.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rootfs"),
+			"Missing rootfs block",
+			"A rootfs block with a storage and size_gb is required to create a container.",
+		)
+		return
+	}
+	for _, attr := range []string{"storage", "size_gb"} {
+		if v, ok := rootfs.Attributes()[attr]; ok && v.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("rootfs").AtName(attr),
+				"Missing rootfs "+attr,
+				"The rootfs block requires a "+attr+".",
+			)
+		}
+	}
+}
+
+func (r *resourceNodeContainer) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var plan, state resourceNodeContainerModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Proxmox is unable to shrink volumes, catch it before anything changes
+	if plan.RootFS != nil && state.RootFS != nil && !plan.RootFS.SizeGB.IsUnknown() &&
+		plan.RootFS.SizeGB.ValueInt64() < state.RootFS.SizeGB.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rootfs").AtName("size_gb"),
+			"Unable to shrink rootfs",
+			"Proxmox does not support shrinking a container volume.",
+		)
+	}
+	for i, mp := range plan.MountPoints {
+		if len(state.MountPoints) <= i {
+			break
+		}
+		current := state.MountPoints[i]
+		if !current.sameSource(mp) {
+			// The volume is moved or a new one is allocated in its place
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("mount_point").AtListIndex(i).AtName("volume_id"), types.StringUnknown())...)
+			if !current.Storage.IsNull() && !mp.movedFrom(current) {
+				resp.Diagnostics.AddAttributeWarning(
+					path.Root("mount_point").AtListIndex(i),
+					"Mount point volume will be detached",
+					fmt.Sprintf("The volume %s is no longer mounted and will be kept as an unused volume of the container.", current.VolumeID.ValueString()),
+				)
+			}
+			if !mp.movedFrom(current) {
+				continue
+			}
+		}
+		if !mp.SizeGB.IsUnknown() && mp.SizeGB.ValueInt64() < state.MountPoints[i].SizeGB.ValueInt64() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("mount_point").AtListIndex(i).AtName("size_gb"),
+				"Unable to shrink mount point",
+				"Proxmox does not support shrinking a container volume.",
+			)
+		}
+	}
+}
+
+func (r *resourceNodeContainer) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourceNodeContainerModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	creq := lxc.CreateRequest{
+		Node:       plan.Node.ValueString(),
+		Vmid:       int(plan.ID.ValueInt64()),
+		Ostemplate: plan.OSTemplate.ValueString(),
+	}
+
+	if plan.Hostname.ValueString() != "" {
+		creq.Hostname = proxmox.String(plan.Hostname.ValueString())
+	}
+	if !plan.Cores.IsNull() {
+		creq.Cores = proxmox.Int(int(plan.Cores.ValueInt64()))
+	}
+	if !plan.Memory.IsNull() {
+		creq.Memory = proxmox.Int(int(plan.Memory.ValueInt64()))
+	}
+	if !plan.Swap.IsNull() {
+		creq.Swap = proxmox.Int(int(plan.Swap.ValueInt64()))
+	}
+	if !plan.Unprivileged.IsNull() {
+		creq.Unprivileged = proxmox.PVEBool(plan.Unprivileged.ValueBool())
+	}
+	if plan.Features != nil {
+		creq.Features = plan.Features.lxcFeatures()
+	}
+	if plan.Password.ValueString() != "" {
+		creq.Password = proxmox.String(plan.Password.ValueString())
+	}
+	if len(plan.SSHPublicKeys) > 0 {
+		keys := make([]string, len(plan.SSHPublicKeys))
+		for i, key := range plan.SSHPublicKeys {
+			keys[i] = key.ValueString()
+		}
+		creq.SshPublicKeys = proxmox.String(strings.Join(keys, "\n"))
+	}
+	if plan.StartOnCreate.ValueBool() {
+		creq.Start = proxmox.PVEBool(true)
+	}
+
+	if plan.RootFS != nil {
+		creq.Rootfs = &lxc.Rootfs{
+			Volume: fmt.Sprintf("%s:%d", plan.RootFS.Storage.ValueString(), plan.RootFS.SizeGB.ValueInt64()),
+		}
+	}
+
+	if len(plan.MountPoints) > 0 {
+		mps := make(lxc.Mps, len(plan.MountPoints))
+		for i, mp := range plan.MountPoints {
+			mps[i] = mp.lxcMp(nil)
+		}
+		creq.Mps = &mps
+	}
+
+	if len(plan.Networks) > 0 {
+		nets := make(lxc.Nets, len(plan.Networks))
+		for i, n := range plan.Networks {
+			nets[i] = n.lxcNet()
+		}
+		creq.Nets = &nets
+	}
+
+	err := retry.OnLock(ctx, "create container", func() error {
+		task, err := r.l.Create(ctx, creq)
+		if err != nil {
+			return err
+		}
+		return r.t.WaitForExit(ctx, task, plan.Node.ValueString())
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating container",
+			"An unexpected error occurred when creating the container. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+
+	config, err := r.l.VmConfig(ctx, lxc.VmConfigRequest{
+		Node: plan.Node.ValueString(),
+		Vmid: int(plan.ID.ValueInt64()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error gettng container config",
+			"An unexpected error occurred when retreiving the container config. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+	plan.setComputed(config)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *resourceNodeContainer) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data resourceNodeContainerModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := retry.OnLock(ctx, "delete container", func() error {
+		task, err := r.l.Delete(ctx, lxc.DeleteRequest{
+			Node:  data.Node.ValueString(),
+			Vmid:  int(data.ID.ValueInt64()),
+			Force: proxmox.PVEBool(true),
+			Purge: proxmox.PVEBool(true),
+		})
+		if err != nil {
+			return err
+		}
+		return r.t.WaitForExit(ctx, task, data.Node.ValueString())
+	})
+	if err != nil {
+		if apierr.Is(err, apierr.ErrGuestNotFound) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error deleting container",
+			"An unexpected error occurred when deleting the container. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *resourceNodeContainer) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan resourceNodeContainerModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state resourceNodeContainerModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	configReq := lxc.UpdateVmConfigRequest{
+		Node: plan.Node.ValueString(),
+		Vmid: int(plan.ID.ValueInt64()),
+	}
+	toDel := []string{}
+
+	if !plan.Hostname.Equal(state.Hostname) {
+		if plan.Hostname.ValueString() == "" {
+			toDel = append(toDel, "hostname")
+		} else {
+			configReq.Hostname = proxmox.String(plan.Hostname.ValueString())
+		}
+	}
+
+	if !plan.Cores.Equal(state.Cores) {
+		if plan.Cores.IsNull() {
+			toDel = append(toDel, "cores")
+		} else {
+			configReq.Cores = proxmox.Int(int(plan.Cores.ValueInt64()))
+		}
+	}
+
+	if !plan.Memory.Equal(state.Memory) {
+		if plan.Memory.IsNull() {
+			toDel = append(toDel, "memory")
+		} else {
+			configReq.Memory = proxmox.Int(int(plan.Memory.ValueInt64()))
+		}
+	}
+
+	if !plan.Swap.Equal(state.Swap) {
+		if plan.Swap.IsNull() {
+			toDel = append(toDel, "swap")
+		} else {
+			configReq.Swap = proxmox.Int(int(plan.Swap.ValueInt64()))
+		}
+	}
+
+	if !plan.Features.Equal(state.Features) {
+		features := plan.Features.lxcFeatures()
+		if features == nil {
+			toDel = append(toDel, "features")
+		} else {
+			configReq.Features = features
+		}
+	}
+
+	if len(plan.Networks) > 0 {
+		nets := make(lxc.Nets, len(plan.Networks))
+		for i, n := range plan.Networks {
+			if len(state.Networks) <= i || !state.Networks[i].Equal(n) {
+				nets[i] = n.lxcNet()
+			}
+		}
+		configReq.Nets = &nets
+	}
+	for i := len(plan.Networks); i < len(state.Networks); i++ {
+		toDel = append(toDel, fmt.Sprintf("net%d", i))
+	}
+
+	// Volumes of removed mount points are moved to unusedN entries and are
+	// destroyed once the config has been updated. Volumes replaced by a host
+	// path are kept as unusedN entries, volumes changing storage are moved.
+	detached := []string{}
+	moved := []int{}
+	if len(plan.MountPoints) > 0 {
+		mps := make(lxc.Mps, len(plan.MountPoints))
+		for i, mp := range plan.MountPoints {
+			var current *MountPoint
+			if len(state.MountPoints) > i {
+				current = state.MountPoints[i]
+			}
+			if current.sameSource(mp) || mp.movedFrom(current) {
+				if !current.optionsEqual(mp) {
+					mps[i] = mp.lxcMp(current)
+				}
+				if mp.movedFrom(current) {
+					moved = append(moved, i)
+				}
+				continue
+			}
+			mps[i] = mp.lxcMp(nil)
+		}
+		configReq.Mps = &mps
+	}
+	for i := len(plan.MountPoints); i < len(state.MountPoints); i++ {
+		toDel = append(toDel, fmt.Sprintf("mp%d", i))
+		if !state.MountPoints[i].Storage.IsNull() {
+			detached = append(detached, state.MountPoints[i].VolumeID.ValueString())
+		}
+	}
+
+	if len(toDel) > 0 {
+		configReq.Delete = proxmox.String(strings.Join(toDel, ","))
+	}
+
+	err := retry.OnLock(ctx, "update container", func() error {
+		return r.l.UpdateVmConfig(ctx, configReq)
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating container",
+			"An unexpected error occurred when updating the container. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+
+	if len(detached) > 0 {
+		resp.Diagnostics.Append(r.destroyUnused(ctx, plan.Node.ValueString(), int(plan.ID.ValueInt64()), detached)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	for _, i := range moved {
+		resp.Diagnostics.Append(r.moveVolume(ctx, plan, i)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	for i, mp := range plan.MountPoints {
+		if len(state.MountPoints) <= i || mp.SizeGB.Equal(state.MountPoints[i].SizeGB) ||
+			!(state.MountPoints[i].sameSource(mp) || mp.movedFrom(state.MountPoints[i])) {
+			continue
+		}
+		resp.Diagnostics.Append(r.resize(ctx, plan, fmt.Sprintf("mp%d", i), mp.SizeGB)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if plan.RootFS != nil && state.RootFS != nil &&
+		!plan.RootFS.SizeGB.Equal(state.RootFS.SizeGB) {
+		resp.Diagnostics.Append(r.resize(ctx, plan, string(lxc.Disk_ROOTFS), plan.RootFS.SizeGB)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	config, err := r.l.VmConfig(ctx, lxc.VmConfigRequest{
+		Node: plan.Node.ValueString(),
+		Vmid: int(plan.ID.ValueInt64()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error gettng container config",
+			"An unexpected error occurred when retreiving the container config. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+	plan.setComputed(config)
+
+	// The creation only values of an imported container are recorded now,
+	// later changes replace the container again
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateImported, []byte("false"))...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *resourceNodeContainer) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state resourceNodeContainerModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config, err := r.l.VmConfig(ctx, lxc.VmConfigRequest{
+		Node: state.Node.ValueString(),
+		Vmid: int(state.ID.ValueInt64()),
+	})
+	if err != nil {
+		if apierr.Is(err, apierr.ErrGuestNotFound) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error gettng container config",
+			"An unexpected error occurred when retreiving the container config. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+
+	defaultHostname := fmt.Sprintf("CT%d", state.ID.ValueInt64())
+	if config.Hostname != nil && (!state.Hostname.IsNull() || *config.Hostname != defaultHostname) {
+		state.Hostname = types.StringValue(*config.Hostname)
+	} else if config.Hostname == nil {
+		state.Hostname = types.StringNull()
+	}
+
+	if config.Cores != nil {
+		state.Cores = types.Int64Value(int64(*config.Cores))
+	} else {
+		state.Cores = types.Int64Null()
+	}
+	state.Memory = optionalInt64(state.Memory, config.Memory, defaultContainerMemory)
+	state.Swap = optionalInt64(state.Swap, config.Swap, defaultContainerSwap)
+	state.Unprivileged = optionalBool(state.Unprivileged, (*bool)(config.Unprivileged), false)
+
+	if config.Features != nil {
+		if state.Features == nil {
+			state.Features = &ContainerFeatures{}
+		}
+		state.Features.buildFeatures(config.Features)
+	} else if state.Features != nil {
+		state.Features.buildFeatures(&lxc.Features{})
+	}
+	state.LXCConfig = buildLXCConfig(config.Lxc)
+
+	if config.Rootfs != nil {
+		if state.RootFS == nil {
+			state.RootFS = &RootFS{}
+		}
+		state.RootFS.buildRootFS(config.Rootfs)
+	} else {
+		state.RootFS = nil
+	}
+
+	if config.Mps != nil {
+		newState := make([]*MountPoint, len(*config.Mps))
+		for i, mp := range *config.Mps {
+			if mp == nil {
+				newState[i] = &MountPoint{}
+				continue
+			}
+			if len(state.MountPoints) <= i || state.MountPoints[i] == nil {
+				newState[i] = &MountPoint{}
+			} else {
+				newState[i] = state.MountPoints[i]
+			}
+			newState[i].buildMountPoint(mp)
+		}
+		state.MountPoints = newState
+	} else {
+		state.MountPoints = make([]*MountPoint, 0)
+	}
+
+	if config.Nets != nil {
+		newState := make([]*ContainerNetwork, len(*config.Nets))
+		for i, net := range *config.Nets {
+			if net == nil {
+				newState[i] = &ContainerNetwork{}
+				continue
+			}
+			if len(state.Networks) <= i || state.Networks[i] == nil {
+				newState[i] = &ContainerNetwork{}
+			} else {
+				newState[i] = state.Networks[i]
+			}
+			newState[i].buildNetwork(net)
+		}
+		state.Networks = newState
+	} else {
+		state.Networks = make([]*ContainerNetwork, 0)
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *resourceNodeContainer) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "@")
+	if len(parts) != 2 {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: node@vmid, got: %q", req.ID),
+		)
+		return
+	}
+	vmid, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected a numeric vmid in import identifier, got: %q", parts[1]),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(
+		resp.State.SetAttribute(ctx, path.Root("id"), vmid)...,
+	)
+	resp.Diagnostics.Append(
+		resp.State.SetAttribute(ctx, path.Root("node"), parts[0])...,
+	)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateImported, []byte("true"))...)
+}
+
+// setComputed fills in the values generated by proxmox on create or update.
+func (m *resourceNodeContainerModel) setComputed(config lxc.VmConfigResponse) {
+	m.LXCConfig = buildLXCConfig(config.Lxc)
+	if m.RootFS != nil {
+		m.RootFS.VolumeID = types.StringNull()
+		if config.Rootfs != nil {
+			m.RootFS.VolumeID = types.StringValue(config.Rootfs.Volume)
+		}
+	}
+	for i, mp := range m.MountPoints {
+		mp.VolumeID = types.StringNull()
+		if config.Mps != nil && len(*config.Mps) > i && (*config.Mps)[i] != nil {
+			mp.VolumeID = types.StringValue((*config.Mps)[i].Volume)
+		}
+	}
+	for i, n := range m.Networks {
+		n.MACAddress = types.StringNull()
+		if config.Nets != nil && len(*config.Nets) > i && (*config.Nets)[i] != nil &&
+			(*config.Nets)[i].Hwaddr != nil {
+			n.MACAddress = types.StringValue(*(*config.Nets)[i].Hwaddr)
+		}
+	}
+}
+
+func (r *RootFS) buildRootFS(rootfs *lxc.Rootfs) {
+	r.VolumeID = types.StringValue(rootfs.Volume)
+	if !strings.HasPrefix(rootfs.Volume, "/") {
+		r.Storage = types.StringValue(strings.Split(rootfs.Volume, ":")[0])
+	}
+	if rootfs.Size != nil {
+		if size, ok := parseSizeGB(*rootfs.Size); ok {
+			r.SizeGB = types.Int64Value(size)
+		}
+	}
+}
+
+// parseSizeGB converts a proxmox disk size (i.e. 8G, 512M, 1T) into GB,
+// rounding up so volumes smaller than a GB are not read as empty.
+func parseSizeGB(size string) (int64, bool) {
+	if size == "" {
+		return 0, false
+	}
+	unit := size[len(size)-1]
+	value, err := strconv.ParseFloat(strings.TrimRight(size, "KMGTkmgt"), 64)
+	if err != nil {
+		return 0, false
+	}
+	switch unit {
+	case 'T', 't':
+		value *= 1024
+	case 'M', 'm':
+		value /= 1024
+	case 'K', 'k':
+		value /= 1024 * 1024
+	case 'G', 'g':
+	default:
+		// A bare number is in bytes
+		value /= 1024 * 1024 * 1024
+	}
+	return int64(math.Ceil(value)), true
+}
+
+func (f *ContainerFeatures) Equal(other *ContainerFeatures) bool {
+	if other == nil && f == nil {
+		return true
+	}
+	if other == nil || f == nil {
+		return false
+	}
+	if len(f.Mount) != len(other.Mount) {
+		return false
+	}
+	for i := range f.Mount {
+		if !f.Mount[i].Equal(other.Mount[i]) {
+			return false
+		}
+	}
+	return f.Nesting.Equal(other.Nesting) &&
+		f.Keyctl.Equal(other.Keyctl) &&
+		f.Fuse.Equal(other.Fuse) &&
+		f.Mknod.Equal(other.Mknod) &&
+		f.ForceRwSys.Equal(other.ForceRwSys)
+}
+
+// lxcFeatures returns the enabled features or nil if none are set.
+func (f *ContainerFeatures) lxcFeatures() *lxc.Features {
+	if f == nil {
+		return nil
+	}
+	features := &lxc.Features{}
+	set := false
+	if f.Nesting.ValueBool() {
+		features.Nesting = proxmox.PVEBool(true)
+		set = true
+	}
+	if f.Keyctl.ValueBool() {
+		features.Keyctl = proxmox.PVEBool(true)
+		set = true
+	}
+	if f.Fuse.ValueBool() {
+		features.Fuse = proxmox.PVEBool(true)
+		set = true
+	}
+	if f.Mknod.ValueBool() {
+		features.Mknod = proxmox.PVEBool(true)
+		set = true
+	}
+	if f.ForceRwSys.ValueBool() {
+		features.ForceRwSys = proxmox.PVEBool(true)
+		set = true
+	}
+	if len(f.Mount) > 0 {
+		mount := make([]string, len(f.Mount))
+		for i, fs := range f.Mount {
+			mount[i] = fs.ValueString()
+		}
+		features.Mount = proxmox.String(strings.Join(mount, ";"))
+		set = true
+	}
+	if !set {
+		return nil
+	}
+	return features
+}
+
+func (f *ContainerFeatures) buildFeatures(features *lxc.Features) {
+	f.Nesting = optionalBool(f.Nesting, (*bool)(features.Nesting), false)
+	f.Keyctl = optionalBool(f.Keyctl, (*bool)(features.Keyctl), false)
+	f.Fuse = optionalBool(f.Fuse, (*bool)(features.Fuse), false)
+	f.Mknod = optionalBool(f.Mknod, (*bool)(features.Mknod), false)
+	f.ForceRwSys = optionalBool(f.ForceRwSys, (*bool)(features.ForceRwSys), false)
+	if features.Mount != nil && *features.Mount != "" {
+		mount := strings.Split(*features.Mount, ";")
+		f.Mount = make([]types.String, len(mount))
+		for i, fs := range mount {
+			f.Mount[i] = types.StringValue(fs)
+		}
+	} else {
+		f.Mount = nil
+	}
+}
+
+func buildLXCConfig(entries *[][]string) []*LXCConfig {
+	config := make([]*LXCConfig, 0)
+	if entries == nil {
+		return config
+	}
+	for _, entry := range *entries {
+		if len(entry) != 2 {
+			continue
+		}
+		config = append(config, &LXCConfig{
+			Key:   types.StringValue(entry[0]),
+			Value: types.StringValue(entry[1]),
+		})
+	}
+	return config
+}
+
+// resize grows a container volume to size GB.
+func (r *resourceNodeContainer) resize(ctx context.Context, plan resourceNodeContainerModel, disk string, size types.Int64) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	err := retry.OnLock(ctx, "resize container "+disk, func() error {
+		task, err := r.l.ResizeVm(ctx, lxc.ResizeVmRequest{
+			Node: plan.Node.ValueString(),
+			Vmid: int(plan.ID.ValueInt64()),
+			Disk: lxc.Disk(disk),
+			Size: fmt.Sprintf("%dG", size.ValueInt64()),
+		})
+		if err != nil {
+			return err
+		}
+		return r.t.WaitForExit(ctx, task, plan.Node.ValueString())
+	})
+	if err != nil {
+		diags.AddError(
+			"Error resizing container volume",
+			"An unexpected error occurred when resizing the container volume "+disk+". "+
+				"Proxmox API Error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// moveVolume moves the volume of mount point i onto its planned storage,
+// the original volume is only removed once it has been copied.
+func (r *resourceNodeContainer) moveVolume(ctx context.Context, plan resourceNodeContainerModel, i int) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	err := retry.OnLock(ctx, fmt.Sprintf("move container volume mp%d", i), func() error {
+		task, err := r.l.MoveVolume(ctx, lxc.MoveVolumeRequest{
+			Node:    plan.Node.ValueString(),
+			Vmid:    int(plan.ID.ValueInt64()),
+			Volume:  lxc.Volume(fmt.Sprintf("mp%d", i)),
+			Storage: proxmox.String(plan.MountPoints[i].Storage.ValueString()),
+			Delete:  proxmox.PVEBool(true),
+		})
+		if err != nil {
+			return err
+		}
+		return r.t.WaitForExit(ctx, task, plan.Node.ValueString())
+	})
+	if err != nil {
+		diags.AddError(
+			"Error moving container volume",
+			fmt.Sprintf("An unexpected error occurred when moving the container volume mp%d. ", i)+
+				"Proxmox API Error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// destroyUnused removes the unusedN entries referencing the given volumes
+// which destroys the volumes.
+func (r *resourceNodeContainer) destroyUnused(ctx context.Context, node string, vmid int, volumes []string) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	config, err := r.l.VmConfig(ctx, lxc.VmConfigRequest{
+		Node: node,
+		Vmid: vmid,
+	})
+	if err != nil {
+		diags.AddError(
+			"Error gettng container config",
+			"An unexpected error occurred when retreiving the container config. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return diags
+	}
+	if config.Unuseds == nil {
+		return diags
+	}
+
+	toDel := []string{}
+	for i, unused := range *config.Unuseds {
+		if unused == nil {
+			continue
+		}
+		for _, volume := range volumes {
+			if unused.Volume == volume {
+				toDel = append(toDel, fmt.Sprintf("unused%d", i))
+			}
+		}
+	}
+	if len(toDel) == 0 {
+		return diags
+	}
+
+	err = retry.OnLock(ctx, "destroy container volumes", func() error {
+		return r.l.UpdateVmConfig(ctx, lxc.UpdateVmConfigRequest{
+			Node:   node,
+			Vmid:   vmid,
+			Delete: proxmox.String(strings.Join(toDel, ",")),
+		})
+	})
+	if err != nil {
+		diags.AddError(
+			"Error destroying container volumes",
+			"An unexpected error occurred when destroying detached container volumes. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// sameSource reports if both mount points are backed by the same volume or
+// host directory, anything else can be changed in place.
+func (m *MountPoint) sameSource(other *MountPoint) bool {
+	if m == nil || other == nil {
+		return false
+	}
+	return m.Storage.Equal(other.Storage) &&
+		m.HostPath.Equal(other.HostPath)
+}
+
+// movedFrom reports if the mount point keeps the volume of current but on a
+// different storage.
+func (m *MountPoint) movedFrom(current *MountPoint) bool {
+	if m == nil || current == nil {
+		return false
+	}
+	return !m.Storage.IsNull() && !current.Storage.IsNull() &&
+		!m.Storage.IsUnknown() && !m.Storage.Equal(current.Storage)
+}
+
+func (m *MountPoint) optionsEqual(other *MountPoint) bool {
+	return m.Path.Equal(other.Path) &&
+		m.Backup.Equal(other.Backup) &&
+		m.ACL.Equal(other.ACL) &&
+		m.Quota.Equal(other.Quota) &&
+		m.Replicate.Equal(other.Replicate) &&
+		m.Readonly.Equal(other.Readonly)
+}
+
+// lxcMp builds the mount point config, reusing the volume of current if set
+// instead of allocating a new one.
+func (m *MountPoint) lxcMp(current *MountPoint) *lxc.Mp {
+	mp := &lxc.Mp{
+		Mp: m.Path.ValueString(),
+	}
+	switch {
+	case current != nil && current.VolumeID.ValueString() != "":
+		mp.Volume = current.VolumeID.ValueString()
+	case !m.HostPath.IsNull():
+		mp.Volume = m.HostPath.ValueString()
+	default:
+		mp.Volume = fmt.Sprintf("%s:%d", m.Storage.ValueString(), m.SizeGB.ValueInt64())
+	}
+	if !m.Backup.IsNull() {
+		mp.Backup = proxmox.PVEBool(m.Backup.ValueBool())
+	}
+	if !m.ACL.IsNull() {
+		mp.Acl = proxmox.PVEBool(m.ACL.ValueBool())
+	}
+	if !m.Quota.IsNull() {
+		mp.Quota = proxmox.PVEBool(m.Quota.ValueBool())
+	}
+	if !m.Replicate.IsNull() {
+		mp.Replicate = proxmox.PVEBool(m.Replicate.ValueBool())
+	}
+	if !m.Readonly.IsNull() {
+		mp.Ro = proxmox.PVEBool(m.Readonly.ValueBool())
+	}
+	return mp
+}
+
+func (m *MountPoint) buildMountPoint(mp *lxc.Mp) {
+	m.Path = types.StringValue(mp.Mp)
+	m.VolumeID = types.StringValue(mp.Volume)
+	if strings.HasPrefix(mp.Volume, "/") {
+		m.HostPath = types.StringValue(mp.Volume)
+		m.Storage = types.StringNull()
+		m.SizeGB = types.Int64Null()
+	} else {
+		m.HostPath = types.StringNull()
+		m.Storage = types.StringValue(strings.Split(mp.Volume, ":")[0])
+		if mp.Size != nil {
+			if size, ok := parseSizeGB(*mp.Size); ok {
+				m.SizeGB = types.Int64Value(size)
+			}
+		}
+	}
+	m.Backup = optionalBool(m.Backup, (*bool)(mp.Backup), false)
+	if mp.Acl != nil {
+		m.ACL = types.BoolValue(bool(*mp.Acl))
+	} else {
+		m.ACL = types.BoolNull()
+	}
+	m.Quota = optionalBool(m.Quota, (*bool)(mp.Quota), false)
+	m.Replicate = optionalBool(m.Replicate, (*bool)(mp.Replicate), true)
+	m.Readonly = optionalBool(m.Readonly, (*bool)(mp.Ro), false)
+}
+
+func (n *ContainerNetwork) Equal(other *ContainerNetwork) bool {
+	if other == nil && n == nil {
+		return true
+	}
+	if other == nil || n == nil {
+		return false
+	}
+	return n.Name.Equal(other.Name) &&
+		n.Bridge.Equal(other.Bridge) &&
+		n.IP.Equal(other.IP) &&
+		n.Gateway.Equal(other.Gateway) &&
+		n.IP6.Equal(other.IP6) &&
+		n.Gateway6.Equal(other.Gateway6) &&
+		n.VLAN.Equal(other.VLAN) &&
+		n.Firewall.Equal(other.Firewall) &&
+		n.MACAddress.Equal(other.MACAddress)
+}
+
+func (n *ContainerNetwork) lxcNet() *lxc.Net {
+	net := &lxc.Net{
+		Name:   n.Name.ValueString(),
+		Bridge: proxmox.String(n.Bridge.ValueString()),
+		Type:   lxc.PtrNetType(lxc.NetType_VETH),
+	}
+	if n.IP.ValueString() != "" {
+		net.Ip = proxmox.String(n.IP.ValueString())
+	}
+	if n.Gateway.ValueString() != "" {
+		net.Gw = proxmox.String(n.Gateway.ValueString())
+	}
+	if n.IP6.ValueString() != "" {
+		net.Ip6 = proxmox.String(n.IP6.ValueString())
+	}
+	if n.Gateway6.ValueString() != "" {
+		net.Gw6 = proxmox.String(n.Gateway6.ValueString())
+	}
+	if !n.VLAN.IsNull() {
+		net.Tag = proxmox.Int(int(n.VLAN.ValueInt64()))
+	}
+	if !n.Firewall.IsNull() {
+		net.Firewall = proxmox.PVEBool(n.Firewall.ValueBool())
+	}
+	// Keep the generated address, otherwise proxmox assigns a new one
+	if n.MACAddress.ValueString() != "" {
+		net.Hwaddr = proxmox.String(n.MACAddress.ValueString())
+	}
+	return net
+}
+
+func (n *ContainerNetwork) buildNetwork(net *lxc.Net) {
+	optionalString := func(v *string) types.String {
+		if v == nil {
+			return types.StringNull()
+		}
+		return types.StringValue(*v)
+	}
+	n.Name = types.StringValue(net.Name)
+	n.Bridge = optionalString(net.Bridge)
+	n.IP = optionalString(net.Ip)
+	n.Gateway = optionalString(net.Gw)
+	n.IP6 = optionalString(net.Ip6)
+	n.Gateway6 = optionalString(net.Gw6)
+	n.MACAddress = optionalString(net.Hwaddr)
+	if net.Tag != nil {
+		n.VLAN = types.Int64Value(int64(*net.Tag))
+	} else {
+		n.VLAN = types.Int64Null()
+	}
+	n.Firewall = optionalBool(n.Firewall, (*bool)(net.Firewall), false)
+}
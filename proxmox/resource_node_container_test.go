@@ -0,0 +1,106 @@
+package proxmox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestParseSizeGB(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int64
+		wantOk bool
+	}{
+		{in: "8G", want: 8, wantOk: true},
+		{in: "1T", want: 1024, wantOk: true},
+		{in: "2048M", want: 2, wantOk: true},
+		{in: "512M", want: 1, wantOk: true},
+		{in: "1536M", want: 2, wantOk: true},
+		{in: "4194304K", want: 4, wantOk: true},
+		{in: "10737418240", want: 10, wantOk: true},
+		{in: "0.5G", want: 1, wantOk: true},
+		{in: "", wantOk: false},
+		{in: "big", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := parseSizeGB(tt.in)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("parseSizeGB(%q) = %d, %t, want %d, %t", tt.in, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestMountPointMovedFrom(t *testing.T) {
+	volume := func(storage string) *MountPoint {
+		return &MountPoint{Storage: types.StringValue(storage), HostPath: types.StringNull()}
+	}
+	bind := &MountPoint{Storage: types.StringNull(), HostPath: types.StringValue("/srv/data")}
+
+	tests := []struct {
+		name    string
+		current *MountPoint
+		planned *MountPoint
+		want    bool
+	}{
+		{name: "storage change", current: volume("local-lvm"), planned: volume("ceph"), want: true},
+		{name: "same storage", current: volume("local-lvm"), planned: volume("local-lvm"), want: false},
+		{name: "volume to host path", current: volume("local-lvm"), planned: bind, want: false},
+		{name: "host path to volume", current: bind, planned: volume("local-lvm"), want: false},
+		{name: "new mount point", current: nil, planned: volume("local-lvm"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.planned.movedFrom(tt.current); got != tt.want {
+				t.Errorf("movedFrom() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateContainerFeatures(t *testing.T) {
+	r := &resourceNodeContainer{}
+	rootfs := testObject(t, testAttributeType(t, r, "rootfs"), map[string]tftypes.Value{
+		"storage": tftypes.NewValue(tftypes.String, "local-lvm"),
+		"size_gb": tftypes.NewValue(tftypes.Number, 8),
+	})
+	featuresType := testAttributeType(t, r, "features")
+	tests := []struct {
+		name         string
+		unprivileged tftypes.Value
+		feature      string
+		wantErr      bool
+	}{
+		{name: "nesting on privileged", unprivileged: tftypes.NewValue(tftypes.Bool, false), feature: "nesting"},
+		{name: "fuse on privileged", unprivileged: tftypes.NewValue(tftypes.Bool, nil), feature: "fuse"},
+		{name: "keyctl on privileged", unprivileged: tftypes.NewValue(tftypes.Bool, false), feature: "keyctl", wantErr: true},
+		{name: "mknod on default", unprivileged: tftypes.NewValue(tftypes.Bool, nil), feature: "mknod", wantErr: true},
+		{name: "force_rw_sys on privileged", unprivileged: tftypes.NewValue(tftypes.Bool, false), feature: "force_rw_sys", wantErr: true},
+		{name: "keyctl on unprivileged", unprivileged: tftypes.NewValue(tftypes.Bool, true), feature: "keyctl"},
+		{name: "mknod on unprivileged", unprivileged: tftypes.NewValue(tftypes.Bool, true), feature: "mknod"},
+		{name: "keyctl on unknown", unprivileged: tftypes.NewValue(tftypes.Bool, tftypes.UnknownValue), feature: "keyctl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := resource.ValidateConfigRequest{
+				Config: testConfig(t, r, map[string]tftypes.Value{
+					"unprivileged": tt.unprivileged,
+					"rootfs":       rootfs,
+					"features": testObject(t, featuresType, map[string]tftypes.Value{
+						tt.feature: tftypes.NewValue(tftypes.Bool, true),
+					}),
+				}),
+			}
+			resp := &resource.ValidateConfigResponse{}
+			r.ValidateConfig(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Errorf("errors = %v, want error %t", resp.Diagnostics, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,21 +2,40 @@ package proxmox
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/FreekingDean/proxmox-api-go/proxmox"
+	"github.com/FreekingDean/proxmox-api-go/proxmox/cluster"
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes"
 	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/storage"
 	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/storage/content"
+	storageconfig "github.com/FreekingDean/proxmox-api-go/proxmox/storage"
 
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/apierr"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/remote"
 	"github.com/FreekingDean/terraform-provider-proxmox/internal/tasks"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/upload"
 )
 
 type IsoModel struct {
@@ -25,23 +44,75 @@ type IsoModel struct {
 	ChecksumAlgorithm types.String `tfsdk:"checksum_algorithm"`
 }
 
+type VztmplModel struct {
+	Url               types.String `tfsdk:"url"`
+	Appliance         types.String `tfsdk:"appliance"`
+	Checksum          types.String `tfsdk:"checksum"`
+	ChecksumAlgorithm types.String `tfsdk:"checksum_algorithm"`
+}
+
+type ImportModel struct {
+	Url               types.String `tfsdk:"url"`
+	Checksum          types.String `tfsdk:"checksum"`
+	ChecksumAlgorithm types.String `tfsdk:"checksum_algorithm"`
+	Decompression     types.String `tfsdk:"decompression"`
+}
+
+type SourceFileModel struct {
+	Path     types.String `tfsdk:"path"`
+	Content  types.String `tfsdk:"content"`
+	Checksum types.String `tfsdk:"checksum"`
+}
+
+type SnippetModel struct {
+	Content  types.String `tfsdk:"content"`
+	Path     types.String `tfsdk:"path"`
+	Checksum types.String `tfsdk:"checksum"`
+}
+
 type resourceNodeStorageContentModel struct {
-	Storage  types.String `tfsdk:"storage"`
-	Filename types.String `tfsdk:"filename"`
-	ID       types.String `tfsdk:"id"`
-	Iso      *IsoModel    `tfsdk:"iso"`
+	Storage    types.String     `tfsdk:"storage"`
+	Filename   types.String     `tfsdk:"filename"`
+	ID         types.String     `tfsdk:"id"`
+	Iso        *IsoModel        `tfsdk:"iso"`
+	Vztmpl     *VztmplModel     `tfsdk:"vztmpl"`
+	SourceFile *SourceFileModel `tfsdk:"source_file"`
+	Import     *ImportModel     `tfsdk:"import"`
+	Snippet    *SnippetModel    `tfsdk:"snippet"`
+	SizeBytes  types.Int64      `tfsdk:"size_bytes"`
+	Format     types.String     `tfsdk:"format"`
+	Ctime      types.Int64      `tfsdk:"ctime"`
+	Used       types.Int64      `tfsdk:"used"`
+	Notes      types.String     `tfsdk:"notes"`
+	Protected  types.Bool       `tfsdk:"protected"`
 }
 
 type resourceNodeStorageContent struct {
-	s *storage.Client
-	t *tasks.Client
-	c *content.Client
+	n  *nodes.Client
+	s  *storageClient
+	t  *tasks.Client
+	c  *content.Client
+	cl *cluster.Client
+	sc *storageconfig.Client
+	u  *upload.Client
+	rc *remote.Client
 }
 
-func (r *resourceNodeStorageContent) SetClient(p *proxmox.Client) {
-	r.s = storage.New(p)
+func (r *resourceNodeStorageContent) SetClient(p apiClient) {
+	r.n = nodes.New(p)
+	r.s = newStorageClient(p)
 	r.t = tasks.New(p)
 	r.c = content.New(p)
+	r.cl = cluster.New(p)
+	r.sc = storageconfig.New(p)
+}
+
+func (r *resourceNodeStorageContent) SetUploader(u *upload.Client) {
+	r.u = u
+}
+
+func (r *resourceNodeStorageContent) SetRemote(rc *remote.Client) {
+	r.rc = rc
 }
 
 func (r *resourceNodeStorageContent) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -55,6 +126,9 @@ func (e *resourceNodeStorageContent) Schema(ctx context.Context, req resource.Sc
 			"id": schema.StringAttribute{
 				Description: "The volid of the content",
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"filename": schema.StringAttribute{
 				Required:    true,
@@ -63,6 +137,48 @@ func (e *resourceNodeStorageContent) Schema(ctx context.Context, req resource.Sc
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"size_bytes": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The size of the content in bytes, a change made outside of terraform replaces the content",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"format": schema.StringAttribute{
+				Computed:    true,
+				Description: "The format of the content (i.e. iso, tgz, qcow2)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ctime": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The creation time of the content (seconds since the UNIX Epoch), a change made outside of terraform replaces the content",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"used": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The used space in bytes, most storages do not report anything useful here",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"notes": schema.StringAttribute{
+				Computed:    true,
+				Description: "Notes attached to the content, proxmox only supports editing them on backups",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"protected": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the content is protected from removal, proxmox only supports protecting backups",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
 			// DG AIP?: /nodes/{node_id}/storage/{storage_id} ??
 			"storage": schema.StringAttribute{
 				Required:    true,
@@ -78,18 +194,165 @@ func (e *resourceNodeStorageContent) Schema(ctx context.Context, req resource.Sc
 				Attributes: map[string]schema.Attribute{
 					"url": schema.StringAttribute{
 						Required:    true,
-						Description: "The url to download the iso from",
+						Description: "The url to download the iso from, changing it replaces the iso",
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
+					"checksum": schema.StringAttribute{
+						Optional:    true,
+						Description: "A checksum of the downloaded content, verified by proxmox after the download but not again on refresh",
+						Validators: []validator.String{
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("checksum_algorithm")),
+						},
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
+					"checksum_algorithm": schema.StringAttribute{
+						Optional:    true,
+						Description: "The checksum algorithm of the downloaded content (md5, sha1, sha224, sha256, sha384 or sha512)",
+						Validators: []validator.String{
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("checksum")),
+							stringvalidator.OneOf(checksumAlgorithms...),
+						},
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
+				},
+			},
+			"import": schema.SingleNestedBlock{
+				Description: "A disk image usable as a virtual machine disk import_from",
+				Attributes: map[string]schema.Attribute{
+					"url": schema.StringAttribute{
+						Required:    true,
+						Description: "The url to download the image from",
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
+					"checksum": schema.StringAttribute{
+						Optional:    true,
+						Description: "A checksum of the downloaded image",
+						Validators: []validator.String{
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("checksum_algorithm")),
+						},
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
+					"checksum_algorithm": schema.StringAttribute{
+						Optional:    true,
+						Description: "The checksum algorithm of the downloaded image",
+						Validators: []validator.String{
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("checksum")),
+							stringvalidator.OneOf(checksumAlgorithms...),
+						},
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
+					"decompression": schema.StringAttribute{
+						Optional:    true,
+						Description: "Decompress the downloaded image, the filename is the decompressed image (gz, lzo, zst, bz2 or xz)",
+						Validators: []validator.String{
+							stringvalidator.OneOf("gz", "lzo", "zst", "bz2", "xz"),
+						},
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
+				},
+			},
+			"source_file": schema.SingleNestedBlock{
+				Description: "A local file uploaded to the storage, replaced when its checksum changes",
+				Attributes: map[string]schema.Attribute{
+					"path": schema.StringAttribute{
+						Required:    true,
+						Description: "The path of the local file to upload",
+					},
+					"content": schema.StringAttribute{
+						Required:    true,
+						Description: "The content type of the file (iso or vztmpl), snippets are written with the snippet block",
+						// The upload endpoint rejects every other content type
+						Validators: []validator.String{
+							stringvalidator.OneOf(
+								string(storage.Content_ISO),
+								string(storage.Content_VZTMPL),
+							),
+						},
 						PlanModifiers: []planmodifier.String{
 							stringplanmodifier.RequiresReplace(),
 						},
 					},
+					"checksum": schema.StringAttribute{
+						Computed:    true,
+						Description: "The sha256 checksum of the uploaded file",
+					},
+				},
+			},
+			"snippet": schema.SingleNestedBlock{
+				Description: "A snippet (i.e. cloud-init user data) written onto the storage over SSH, it is written again when its content changes. Requires SSH credentials on the provider and a directory backed storage.",
+				Attributes: map[string]schema.Attribute{
+					"content": schema.StringAttribute{
+						Optional:    true,
+						Description: "The content of the snippet",
+						Validators: []validator.String{
+							stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("path")),
+						},
+					},
+					"path": schema.StringAttribute{
+						Optional:    true,
+						Description: "The path of a local file holding the content of the snippet",
+					},
+					"checksum": schema.StringAttribute{
+						Computed:    true,
+						Description: "The sha256 checksum of the snippet, a snippet changed outside of terraform is written again",
+					},
+				},
+			},
+			"vztmpl": schema.SingleNestedBlock{
+				Description: "A container template object",
+				Attributes: map[string]schema.Attribute{
+					"url": schema.StringAttribute{
+						Optional:    true,
+						Description: "The url to download the template from",
+						Validators: []validator.String{
+							stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("appliance")),
+						},
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
+					"appliance": schema.StringAttribute{
+						Optional:    true,
+						Description: "The name of a template in the nodes appliance index, the filename must match it",
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
+					},
 					"checksum": schema.StringAttribute{
 						Optional:    true,
-						Description: "A checksum of the downlaoded content",
+						Description: "A checksum of the downloaded template",
+						Validators: []validator.String{
+							stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("appliance")),
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("checksum_algorithm")),
+						},
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
 					},
 					"checksum_algorithm": schema.StringAttribute{
 						Optional:    true,
-						Description: "The checksum algorithm of the downlaoded content",
+						Description: "The checksum algorithm of the downloaded template",
+						Validators: []validator.String{
+							stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("checksum")),
+							stringvalidator.OneOf(checksumAlgorithms...),
+						},
+						PlanModifiers: []planmodifier.String{
+							requiresReplaceUnlessImportedString(),
+						},
 					},
 				},
 			},
@@ -97,14 +360,204 @@ func (e *resourceNodeStorageContent) Schema(ctx context.Context, req resource.Sc
 	}
 }
 
-// TODO: Add once more blocks added
-//func (r *resourceNodeStorageContent) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
-//	return []resource.ConfigValidator{
-//		resourcevalidator.Conflicting(
-//			path.MatchRoot("iso"),
-//		),
-//	}
-//}
+func (r *resourceNodeStorageContent) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("iso"),
+			path.MatchRoot("vztmpl"),
+			path.MatchRoot("source_file"),
+			path.MatchRoot("import"),
+			path.MatchRoot("snippet"),
+		),
+	}
+}
+
+func (r *resourceNodeStorageContent) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var filename, appliance types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("filename"), &filename)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("vztmpl").AtName("appliance"), &appliance)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var image, snippet types.Object
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("import"), &image)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("snippet"), &snippet)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !image.IsNull() && !filename.IsUnknown() && !importFilenameRegex.MatchString(filename.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filename"),
+			"Invalid import filename",
+			"Disk images must be named with a .qcow2, .raw, .img or .vmdk extension.",
+		)
+	}
+	if !snippet.IsNull() && !filename.IsUnknown() && !snippetFilenameRegex.MatchString(filename.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filename"),
+			"Invalid snippet filename",
+			"Snippets are stored directly in the snippets directory, the filename must not contain a \"/\" or start with a \".\".",
+		)
+	}
+
+	// Appliances are always stored under their template name
+	if !appliance.IsNull() && !appliance.IsUnknown() && !filename.IsUnknown() &&
+		appliance.ValueString() != filename.ValueString() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filename"),
+			"Invalid appliance filename",
+			fmt.Sprintf("The filename must match the appliance template %q.", appliance.ValueString()),
+		)
+	}
+}
+
+func (r *resourceNodeStorageContent) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan resourceNodeStorageContentModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state resourceNodeStorageContentModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// Snippets are compared by their checksum instead
+		if state.Snippet == nil {
+			resp.Diagnostics.Append(r.planReplaced(ctx, req.Private, resp, state)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+	}
+
+	if plan.Snippet != nil {
+		resp.Diagnostics.Append(planSnippet(ctx, req, resp, plan.Snippet)...)
+		return
+	}
+
+	if plan.SourceFile == nil || plan.SourceFile.Path.IsUnknown() {
+		return
+	}
+
+	// Hash the local file so a changed file shows up in the plan, the hash is
+	// reused while the size and modification time stay the same
+	source, err := statSourceFile(plan.SourceFile.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("source_file").AtName("path"),
+			"Error reading source file",
+			"An unexpected error occurred when reading the source file. "+
+				"Error: "+err.Error(),
+		)
+		return
+	}
+	cached, diags := getSourceFile(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if cached != nil && cached.sameFile(source) {
+		source.Sha256 = cached.Sha256
+	} else {
+		source.Sha256, err = upload.Sha256(source.Path)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("source_file").AtName("path"),
+				"Error reading source file",
+				"An unexpected error occurred when reading the source file. "+
+					"Error: "+err.Error(),
+			)
+			return
+		}
+		resp.Diagnostics.Append(setSourceFile(ctx, resp.Private, source)...)
+	}
+	sum := source.Sha256
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("source_file").AtName("checksum"), sum)...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var state resourceNodeStorageContentModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if state.SourceFile == nil || state.SourceFile.Checksum.ValueString() != sum {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("source_file").AtName("checksum"))
+	}
+}
+
+// planReplaced plans a fresh copy of content replaced out of band, the size
+// and creation time read into state no longer match the fingerprint taken
+// when terraform wrote the content.
+func (r *resourceNodeStorageContent) planReplaced(ctx context.Context, private interface {
+	GetKey(context.Context, string) ([]byte, diag.Diagnostics)
+}, resp *resource.ModifyPlanResponse, state resourceNodeStorageContentModel) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+
+	fingerprint, d := getFingerprint(ctx, private)
+	diags.Append(d...)
+	if fingerprint == nil || !fingerprint.replacedBy(state) {
+		return diags
+	}
+	diags.Append(unknownVolumeInfo(ctx, resp)...)
+	resp.RequiresReplace = append(resp.RequiresReplace, path.Root("size_bytes"), path.Root("ctime"))
+	return diags
+}
+
+// planSnippet plans the checksum of the configured snippet, a checksum
+// differing from the snippet on the storage writes it again.
+func planSnippet(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, snippet *SnippetModel) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	checksum := types.StringUnknown()
+	if !snippet.Content.IsUnknown() && !snippet.Path.IsUnknown() {
+		sum, err := snippetChecksum(snippet)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("snippet").AtName("path"),
+				"Error reading snippet",
+				"An unexpected error occurred when reading the snippet. "+
+					"Error: "+err.Error(),
+			)
+			return diags
+		}
+		checksum = types.StringValue(sum)
+	}
+	diags.Append(resp.Plan.SetAttribute(ctx, path.Root("snippet").AtName("checksum"), checksum)...)
+
+	if req.State.Raw.IsNull() {
+		return diags
+	}
+	var state types.String
+	diags.Append(req.State.GetAttribute(ctx, path.Root("snippet").AtName("checksum"), &state)...)
+	if !state.Equal(checksum) {
+		diags.Append(unknownVolumeInfo(ctx, resp)...)
+	}
+	return diags
+}
+
+// snippetChecksum returns the hex encoded sha256 sum of the snippet content.
+func snippetChecksum(snippet *SnippetModel) (string, error) {
+	if !snippet.Path.IsNull() {
+		return upload.Sha256(snippet.Path.ValueString())
+	}
+	sum := sha256.Sum256([]byte(snippet.Content.ValueString()))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// unknownVolumeInfo marks the computed volume attributes as changing.
+func unknownVolumeInfo(ctx context.Context, resp *resource.ModifyPlanResponse) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	diags.Append(resp.Plan.SetAttribute(ctx, path.Root("size_bytes"), types.Int64Unknown())...)
+	diags.Append(resp.Plan.SetAttribute(ctx, path.Root("format"), types.StringUnknown())...)
+	diags.Append(resp.Plan.SetAttribute(ctx, path.Root("ctime"), types.Int64Unknown())...)
+	diags.Append(resp.Plan.SetAttribute(ctx, path.Root("used"), types.Int64Unknown())...)
+	return diags
+}
 
 func (r *resourceNodeStorageContent) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan resourceNodeStorageContentModel
@@ -126,17 +579,71 @@ func (r *resourceNodeStorageContent) Create(ctx context.Context, req resource.Cr
 	format := ""
 	if plan.Iso != nil {
 		format = "iso"
-		outStr, err := r.s.DownloadUrl(ctx, storage.DownloadUrlRequest{
-			Content:  "iso",
+		resp.Diagnostics.Append(r.downloadIso(ctx, id, plan.Filename.ValueString(), plan.Iso)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if plan.Vztmpl != nil {
+		format = "vztmpl"
+		var outStr string
+		if plan.Vztmpl.Appliance.ValueString() != "" {
+			outStr, err = r.n.AplDownloadAplinfo(ctx, nodes.AplDownloadAplinfoRequest{
+				Node:     id.Node,
+				Storage:  id.Storage,
+				Template: plan.Vztmpl.Appliance.ValueString(),
+			})
+		} else {
+			dreq := storage.DownloadUrlRequest{
+				Content:  storage.Content_VZTMPL,
+				Filename: plan.Filename.ValueString(),
+				Url:      plan.Vztmpl.Url.ValueString(),
+				Node:     id.Node,
+				Storage:  id.Storage,
+			}
+			if plan.Vztmpl.Checksum.ValueString() != "" {
+				dreq.Checksum = proxmox.String(plan.Vztmpl.Checksum.ValueString())
+				algorithm := storage.ChecksumAlgorithm(plan.Vztmpl.ChecksumAlgorithm.ValueString())
+				dreq.ChecksumAlgorithm = &algorithm
+			}
+			outStr, err = r.s.DownloadUrl(ctx, dreq)
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error downloading container template",
+				"An unexpected error occurred when downloading the container template. "+
+					"Proxmox Client Error: "+err.Error(),
+			)
+			return
+		}
+		resp.Diagnostics.Append(r.t.Wait(ctx, outStr, id.Node)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if plan.Import != nil {
+		format = string(contentImport)
+		dreq := storage.DownloadUrlRequest{
+			Content:  contentImport,
 			Filename: plan.Filename.ValueString(),
-			Url:      plan.Iso.Url.ValueString(),
+			Url:      plan.Import.Url.ValueString(),
 			Node:     id.Node,
 			Storage:  id.Storage,
-		})
+		}
+		if plan.Import.Checksum.ValueString() != "" {
+			dreq.Checksum = proxmox.String(plan.Import.Checksum.ValueString())
+			algorithm := storage.ChecksumAlgorithm(plan.Import.ChecksumAlgorithm.ValueString())
+			dreq.ChecksumAlgorithm = &algorithm
+		}
+		raw := rawConfig{}
+		if plan.Import.Decompression.ValueString() != "" {
+			raw["compression"] = plan.Import.Decompression.ValueString()
+		}
+		outStr, err := r.s.DownloadUrlWithRaw(ctx, dreq, raw)
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error downloading iso",
-				"An unexpected error occurred when downloading ISO. "+
+				"Error downloading disk image",
+				"An unexpected error occurred when downloading the disk image. "+
 					"Proxmox Client Error: "+err.Error(),
 			)
 			return
@@ -146,9 +653,57 @@ func (r *resourceNodeStorageContent) Create(ctx context.Context, req resource.Cr
 			return
 		}
 	}
+	if plan.SourceFile != nil {
+		format = plan.SourceFile.Content.ValueString()
+		outStr, err := r.u.File(ctx, upload.Request{
+			Node:              id.Node,
+			Storage:           id.Storage,
+			Content:           format,
+			Filename:          plan.Filename.ValueString(),
+			Checksum:          plan.SourceFile.Checksum.ValueString(),
+			ChecksumAlgorithm: string(storage.ChecksumAlgorithm_SHA256),
+		}, plan.SourceFile.Path.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error uploading file",
+				"An unexpected error occurred when uploading the source file. "+
+					"Proxmox Client Error: "+err.Error(),
+			)
+			return
+		}
+		resp.Diagnostics.Append(r.t.Wait(ctx, outStr, id.Node)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// proxmox verified the upload against the planned checksum
+		source, err := statSourceFile(plan.SourceFile.Path.ValueString())
+		if err == nil {
+			source.Sha256 = plan.SourceFile.Checksum.ValueString()
+			resp.Diagnostics.Append(setSourceFile(ctx, resp.Private, source)...)
+		}
+	}
+	if plan.Snippet != nil {
+		format = string(contentSnippets)
+		resp.Diagnostics.Append(r.writeSnippet(ctx, id, plan.Filename.ValueString(), plan.Snippet)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 	plan.ID = types.StringValue(
 		fmt.Sprintf("%s:%s/%s", id.Storage, format, plan.Filename.ValueString()),
 	)
+
+	fingerprint, err := r.readContent(ctx, id, &plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error retreiving content information",
+			"An unexpected error occurred when retreiving content information. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+	resp.Diagnostics.Append(setFingerprint(ctx, resp.Private, fingerprint)...)
+
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -174,6 +729,10 @@ func (r *resourceNodeStorageContent) Delete(ctx context.Context, req resource.De
 		)
 		return
 	}
+	if data.Snippet != nil {
+		resp.Diagnostics.Append(r.removeSnippet(ctx, id, data.Filename.ValueString())...)
+		return
+	}
 	taskID, err := r.c.Delete(ctx, content.DeleteRequest{
 		Node:    id.Node,
 		Volume:  data.ID.ValueString(),
@@ -201,6 +760,43 @@ func (r *resourceNodeStorageContent) Update(ctx context.Context, req resource.Up
 		return
 	}
 
+	id := &StorageID{}
+	err := id.SScan(plan.Storage.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error parsing storage identifier",
+			"An unexpected error occurred when parsing the storage identifier. "+
+				"Error: "+err.Error(),
+		)
+		return
+	}
+
+	if plan.Snippet != nil {
+		var state resourceNodeStorageContentModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if state.Snippet == nil || !state.Snippet.Checksum.Equal(plan.Snippet.Checksum) {
+			resp.Diagnostics.Append(r.writeSnippet(ctx, id, plan.Filename.ValueString(), plan.Snippet)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+	}
+
+	fingerprint, err := r.readContent(ctx, id, &plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error retreiving content information",
+			"An unexpected error occurred when retreiving content information. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+	resp.Diagnostics.Append(setFingerprint(ctx, resp.Private, fingerprint)...)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateImported, []byte("false"))...)
+
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -226,21 +822,44 @@ func (r *resourceNodeStorageContent) Read(ctx context.Context, req resource.Read
 		)
 		return
 	}
-	_, err = r.c.Find(ctx, content.FindRequest{
-		Node:    id.Node,
-		Volume:  state.ID.ValueString(),
-		Storage: &id.Storage,
-	})
-	if err != nil && !strings.Contains(err.Error(), "volume_size_info on") {
+	current, err := r.readContent(ctx, id, &state)
+	if apierr.Is(err, apierr.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error retreiving content information",
 			"An unexpected error occurred when retreiving content information. "+
 				"Proxmox API Error: "+err.Error(),
 		)
 		return
-	} else if err != nil {
-		resp.State.RemoveResource(ctx)
-		return
+	}
+
+	if state.Snippet != nil && r.rc != nil {
+		sum, err := r.storedSnippetChecksum(ctx, id, state.Filename.ValueString())
+		if errors.Is(err, os.ErrNotExist) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading snippet",
+				"An unexpected error occurred when reading the snippet over SSH. "+
+					"Error: "+err.Error(),
+			)
+			return
+		}
+		state.Snippet.Checksum = types.StringValue(sum)
+	}
+
+	// Proxmox does not report checksums, the size and creation time of the
+	// content as it was written are the best indicator it was replaced. The
+	// difference to the fingerprint is planned as a replacement.
+	fingerprint, diags := getFingerprint(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if fingerprint == nil {
+		resp.Diagnostics.Append(setFingerprint(ctx, resp.Private, current)...)
 	}
 
 	diags = resp.State.Set(ctx, &state)
@@ -250,6 +869,188 @@ func (r *resourceNodeStorageContent) Read(ctx context.Context, req resource.Read
 	}
 }
 
+// downloadIso downloads the iso into filename, proxmox verifies the checksum
+// before the file is moved into place.
+func (r *resourceNodeStorageContent) downloadIso(ctx context.Context, id *StorageID, filename string, iso *IsoModel) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	dreq := storage.DownloadUrlRequest{
+		Content:  "iso",
+		Filename: filename,
+		Url:      iso.Url.ValueString(),
+		Node:     id.Node,
+		Storage:  id.Storage,
+	}
+	if iso.Checksum.ValueString() != "" {
+		dreq.Checksum = proxmox.String(iso.Checksum.ValueString())
+		algorithm := storage.ChecksumAlgorithm(iso.ChecksumAlgorithm.ValueString())
+		dreq.ChecksumAlgorithm = &algorithm
+	}
+	err := r.wait(ctx, id.Node, func() (string, error) {
+		return r.s.DownloadUrl(ctx, dreq)
+	})
+	if err != nil {
+		diags.AddError(
+			"Error downloading iso",
+			"An unexpected error occurred when downloading ISO. "+
+				"Proxmox Client Error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// writeSnippet writes the snippet onto the storage, it is only moved into
+// place once it matches the planned checksum.
+func (r *resourceNodeStorageContent) writeSnippet(ctx context.Context, id *StorageID, filename string, snippet *SnippetModel) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	if snippet.Checksum.IsUnknown() {
+		sum, err := snippetChecksum(snippet)
+		if err != nil {
+			diags.AddError(
+				"Error reading snippet",
+				"An unexpected error occurred when reading the snippet. "+
+					"Error: "+err.Error(),
+			)
+			return diags
+		}
+		snippet.Checksum = types.StringValue(sum)
+	}
+
+	var data io.Reader = strings.NewReader(snippet.Content.ValueString())
+	if !snippet.Path.IsNull() {
+		f, err := os.Open(snippet.Path.ValueString())
+		if err != nil {
+			diags.AddError(
+				"Error reading snippet",
+				"An unexpected error occurred when reading the snippet. "+
+					"Error: "+err.Error(),
+			)
+			return diags
+		}
+		defer f.Close()
+		data = f
+	}
+
+	host, name, err := r.snippetLocation(ctx, id, filename)
+	if err == nil {
+		err = r.rc.Write(ctx, host, name, data, snippet.Checksum.ValueString())
+	}
+	if err != nil {
+		diags.AddError(
+			"Error writing snippet",
+			"An unexpected error occurred when writing the snippet over SSH. "+
+				"Error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// removeSnippet removes the snippet from the storage.
+func (r *resourceNodeStorageContent) removeSnippet(ctx context.Context, id *StorageID, filename string) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	host, name, err := r.snippetLocation(ctx, id, filename)
+	if err == nil {
+		err = r.rc.Remove(ctx, host, name)
+	}
+	if err != nil {
+		diags.AddError(
+			"Error removing snippet",
+			"An unexpected error occurred when removing the snippet over SSH. "+
+				"Error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// storedSnippetChecksum returns the checksum of the snippet on the storage.
+func (r *resourceNodeStorageContent) storedSnippetChecksum(ctx context.Context, id *StorageID, filename string) (string, error) {
+	host, name, err := r.snippetLocation(ctx, id, filename)
+	if err != nil {
+		return "", err
+	}
+	return r.rc.Sha256(ctx, host, name)
+}
+
+// snippetLocation returns the address of the node and the path of the
+// snippet filename on the storage.
+func (r *resourceNodeStorageContent) snippetLocation(ctx context.Context, id *StorageID, filename string) (string, string, error) {
+	if r.rc == nil {
+		return "", "", fmt.Errorf("snippets are written over SSH, configure ssh_password or ssh_private_key on the provider")
+	}
+	config, err := r.sc.Find(ctx, storageconfig.FindRequest{Storage: id.Storage})
+	if err != nil {
+		return "", "", err
+	}
+	dir, _ := config["path"].(string)
+	if dir == "" {
+		return "", "", fmt.Errorf("storage %s is not backed by a directory", id.Storage)
+	}
+
+	// The node name does not have to resolve outside of the cluster
+	host := id.Node
+	members, err := r.cl.GetStatus(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	for _, member := range members {
+		if member.Type == cluster.Type_NODE && member.Name == id.Node && member.Ip != nil {
+			host = *member.Ip
+		}
+	}
+	return host, strings.TrimSuffix(dir, "/") + "/snippets/" + filename, nil
+}
+
+// readContent fills the computed volume attributes of m and returns the
+// fingerprint of the content.
+func (r *resourceNodeStorageContent) readContent(ctx context.Context, id *StorageID, m *resourceNodeStorageContentModel) (contentFingerprint, error) {
+	fingerprint := contentFingerprint{}
+	info, err := r.c.Find(ctx, content.FindRequest{
+		Node:    id.Node,
+		Volume:  m.ID.ValueString(),
+		Storage: &id.Storage,
+	})
+	if err != nil {
+		return fingerprint, err
+	}
+	m.SizeBytes = types.Int64Value(int64(info.Size))
+	m.Format = types.StringValue(info.Format)
+	m.Used = types.Int64Value(int64(info.Used))
+	m.Notes = types.StringValue("")
+	if info.Notes != nil {
+		m.Notes = types.StringValue(*info.Notes)
+	}
+	m.Protected = types.BoolValue(info.Protected != nil && bool(*info.Protected))
+
+	// The creation time is only part of the storage listing
+	m.Ctime = types.Int64Null()
+	contentType := strings.SplitN(strings.TrimPrefix(m.ID.ValueString(), id.Storage+":"), "/", 2)[0]
+	volumes, err := r.c.Index(ctx, content.IndexRequest{
+		Node:    id.Node,
+		Storage: id.Storage,
+		Content: &contentType,
+	})
+	if err != nil {
+		return fingerprint, err
+	}
+	for _, volume := range volumes {
+		if volume.Volid == m.ID.ValueString() && volume.Ctime != nil {
+			m.Ctime = types.Int64Value(int64(*volume.Ctime))
+		}
+	}
+
+	fingerprint.Size = m.SizeBytes.ValueInt64()
+	fingerprint.Ctime = m.Ctime.ValueInt64()
+	return fingerprint, nil
+}
+
+// wait starts a task and waits for it to exit.
+func (r *resourceNodeStorageContent) wait(ctx context.Context, node string, start func() (string, error)) error {
+	task, err := start()
+	if err != nil {
+		return err
+	}
+	return r.t.WaitForExit(ctx, task, node)
+}
+
 func (r *resourceNodeStorageContent) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	parts := strings.Split(req.ID, "@")
 	if len(parts) != 2 {
@@ -296,6 +1097,133 @@ func (r *resourceNodeStorageContent) ImportState(ctx context.Context, req resour
 			resp.State.SetAttribute(ctx, path.Root("iso"), &IsoModel{})...,
 		)
 	}
+	if format == string(contentImport) {
+		resp.Diagnostics.Append(
+			resp.State.SetAttribute(ctx, path.Root("import"), &ImportModel{})...,
+		)
+	}
+	if format == "vztmpl" {
+		resp.Diagnostics.Append(
+			resp.State.SetAttribute(ctx, path.Root("vztmpl"), &VztmplModel{})...,
+		)
+	}
+	if format == string(contentSnippets) {
+		resp.Diagnostics.Append(
+			resp.State.SetAttribute(ctx, path.Root("snippet"), &SnippetModel{})...,
+		)
+	}
+	// The download source is unknown, it is recorded on the first apply
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateImported, []byte("true"))...)
+}
+
+// privateFingerprint is the private state key holding the contentFingerprint
+// of the content as it was written by terraform.
+const privateFingerprint = "fingerprint"
+
+type contentFingerprint struct {
+	Size  int64 `json:"size"`
+	Ctime int64 `json:"ctime"`
+}
+
+// replacedBy reports if the content read into m is not the content the
+// fingerprint was taken of.
+func (f *contentFingerprint) replacedBy(m resourceNodeStorageContentModel) bool {
+	return f.Size != m.SizeBytes.ValueInt64() || f.Ctime != m.Ctime.ValueInt64()
+}
+
+func getFingerprint(ctx context.Context, p interface {
+	GetKey(context.Context, string) ([]byte, diag.Diagnostics)
+}) (*contentFingerprint, diag.Diagnostics) {
+	value, diags := p.GetKey(ctx, privateFingerprint)
+	if diags.HasError() || value == nil {
+		return nil, diags
+	}
+	fingerprint := &contentFingerprint{}
+	if err := json.Unmarshal(value, fingerprint); err != nil {
+		return nil, diags
+	}
+	return fingerprint, diags
+}
+
+func setFingerprint(ctx context.Context, p interface {
+	SetKey(context.Context, string, []byte) diag.Diagnostics
+}, fingerprint contentFingerprint) diag.Diagnostics {
+	value, err := json.Marshal(fingerprint)
+	if err != nil {
+		diags := diag.Diagnostics{}
+		diags.AddError("Error saving content fingerprint", err.Error())
+		return diags
+	}
+	return p.SetKey(ctx, privateFingerprint, value)
+}
+
+// privateSourceFile is the private state key caching the checksum of the
+// source file, it is only hashed again when its size or modification time
+// changes.
+const privateSourceFile = "source_file"
+
+type sourceFileStat struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+	Sha256  string `json:"sha256"`
+}
+
+func statSourceFile(path string) (sourceFileStat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return sourceFileStat{}, err
+	}
+	return sourceFileStat{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+	}, nil
+}
+
+// sameFile reports if other is the same, unmodified, file.
+func (s *sourceFileStat) sameFile(other sourceFileStat) bool {
+	return s.Sha256 != "" && s.Path == other.Path &&
+		s.Size == other.Size && s.ModTime == other.ModTime
+}
+
+func getSourceFile(ctx context.Context, p interface {
+	GetKey(context.Context, string) ([]byte, diag.Diagnostics)
+}) (*sourceFileStat, diag.Diagnostics) {
+	value, diags := p.GetKey(ctx, privateSourceFile)
+	if diags.HasError() || value == nil {
+		return nil, diags
+	}
+	source := &sourceFileStat{}
+	if err := json.Unmarshal(value, source); err != nil {
+		return nil, diags
+	}
+	return source, diags
+}
+
+func setSourceFile(ctx context.Context, p interface {
+	SetKey(context.Context, string, []byte) diag.Diagnostics
+}, source sourceFileStat) diag.Diagnostics {
+	value, err := json.Marshal(source)
+	if err != nil {
+		diags := diag.Diagnostics{}
+		diags.AddError("Error saving source file checksum", err.Error())
+		return diags
+	}
+	return p.SetKey(ctx, privateSourceFile, value)
+}
+
+var importFilenameRegex = regexp.MustCompile(`\.(qcow2|raw|img|vmdk)$`)
+
+var snippetFilenameRegex = regexp.MustCompile(`^[^/.][^/]*$`)
+
+var checksumAlgorithms = []string{
+	string(storage.ChecksumAlgorithm_MD5),
+	string(storage.ChecksumAlgorithm_SHA1),
+	string(storage.ChecksumAlgorithm_SHA224),
+	string(storage.ChecksumAlgorithm_SHA256),
+	string(storage.ChecksumAlgorithm_SHA384),
+	string(storage.ChecksumAlgorithm_SHA512),
 }
 
 type StorageID struct {
@@ -0,0 +1,39 @@
+package proxmox
+
+import (
+	"context"
+
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/storage"
+)
+
+// Content types the generated storage types do not know about yet
+const (
+	contentImport   storage.Content = "import"
+	contentSnippets storage.Content = "snippets"
+)
+
+type rawDownloadUrlRequest struct {
+	storage.DownloadUrlRequest
+	Raw rawConfig `url:"raw,omitempty"`
+}
+
+type storageClient struct {
+	*storage.Client
+	p storage.HTTPClient
+}
+
+func newStorageClient(p storage.HTTPClient) *storageClient {
+	return &storageClient{
+		Client: storage.New(p),
+		p:      p,
+	}
+}
+
+// DownloadUrlWithRaw downloads with options missing from the generated
+// request (i.e. compression)
+func (c *storageClient) DownloadUrlWithRaw(ctx context.Context, req storage.DownloadUrlRequest, raw rawConfig) (string, error) {
+	var resp string
+
+	err := c.p.Do(ctx, "/nodes/{node}/storage/{storage}/download-url", "POST", &resp, rawDownloadUrlRequest{req, raw})
+	return resp, err
+}
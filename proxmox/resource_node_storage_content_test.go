@@ -0,0 +1,354 @@
+package proxmox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/FreekingDean/proxmox-api-go/proxmox"
+)
+
+func TestSourceFileStatSameFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debian.qcow2")
+	if err := os.WriteFile(path, []byte("a: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := statSourceFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached.Sha256 = "cached"
+
+	current, err := statSourceFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cached.sameFile(current) {
+		t.Error("an unmodified file should reuse the cached checksum")
+	}
+
+	moved := current
+	moved.Path = path + ".old"
+	if cached.sameFile(moved) {
+		t.Error("a different path should be hashed again")
+	}
+
+	// Same size, only the modification time changes
+	if err := os.WriteFile(path, []byte("a: 2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Unix(0, cached.ModTime).Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	current, err = statSourceFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.sameFile(current) {
+		t.Error("a modified file should be hashed again")
+	}
+
+	empty := sourceFileStat{Path: cached.Path, Size: cached.Size, ModTime: cached.ModTime}
+	if empty.sameFile(cached) {
+		t.Error("a cache without a checksum should not be used")
+	}
+}
+
+func TestContentFingerprintReplacedBy(t *testing.T) {
+	fingerprint := &contentFingerprint{Size: 1024, Ctime: 1700000000}
+	tests := []struct {
+		name  string
+		size  types.Int64
+		ctime types.Int64
+		want  bool
+	}{
+		{name: "unchanged", size: types.Int64Value(1024), ctime: types.Int64Value(1700000000), want: false},
+		{name: "size changed", size: types.Int64Value(2048), ctime: types.Int64Value(1700000000), want: true},
+		{name: "rewritten with the same size", size: types.Int64Value(1024), ctime: types.Int64Value(1700000100), want: true},
+		{name: "creation time no longer reported", size: types.Int64Value(1024), ctime: types.Int64Null(), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := resourceNodeStorageContentModel{SizeBytes: tt.size, Ctime: tt.ctime}
+			if got := fingerprint.replacedBy(m); got != tt.want {
+				t.Errorf("replacedBy() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanReplaced(t *testing.T) {
+	r := &resourceNodeStorageContent{}
+	tests := []struct {
+		name        string
+		fingerprint string
+		size        int64
+		ctime       int64
+		wantReplace bool
+	}{
+		{name: "no fingerprint", size: 1024, ctime: 1700000000},
+		{name: "unchanged", fingerprint: `{"size":1024,"ctime":1700000000}`, size: 1024, ctime: 1700000000},
+		{name: "size changed", fingerprint: `{"size":1024,"ctime":1700000000}`, size: 4096, ctime: 1700000000, wantReplace: true},
+		{name: "ctime changed", fingerprint: `{"size":1024,"ctime":1700000000}`, size: 1024, ctime: 1700000100, wantReplace: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig(t, r, map[string]tftypes.Value{
+				"id":         tftypes.NewValue(tftypes.String, "local:iso/a.iso"),
+				"size_bytes": tftypes.NewValue(tftypes.Number, tt.size),
+				"ctime":      tftypes.NewValue(tftypes.Number, tt.ctime),
+			})
+			private := fakePrivate{}
+			if tt.fingerprint != "" {
+				private[privateFingerprint] = []byte(tt.fingerprint)
+			}
+			state := resourceNodeStorageContentModel{
+				SizeBytes: types.Int64Value(tt.size),
+				Ctime:     types.Int64Value(tt.ctime),
+			}
+			resp := &resource.ModifyPlanResponse{
+				Plan: tfsdk.Plan{Schema: config.Schema, Raw: config.Raw},
+			}
+			diags := r.planReplaced(context.Background(), private, resp, state)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			wantPaths := map[string]bool{}
+			if tt.wantReplace {
+				wantPaths = map[string]bool{"size_bytes": true, "ctime": true}
+			}
+			gotPaths := map[string]bool{}
+			for _, p := range resp.RequiresReplace {
+				gotPaths[p.String()] = true
+			}
+			if len(gotPaths) != len(wantPaths) {
+				t.Errorf("RequiresReplace = %v, want %v", resp.RequiresReplace, wantPaths)
+			}
+			for p := range wantPaths {
+				if !gotPaths[p] {
+					t.Errorf("RequiresReplace = %v, missing %s", resp.RequiresReplace, p)
+				}
+			}
+
+			var size types.Int64
+			resp.Plan.GetAttribute(context.Background(), path.Root("size_bytes"), &size)
+			if size.IsUnknown() != tt.wantReplace {
+				t.Errorf("planned size_bytes = %v, want unknown %t", size, tt.wantReplace)
+			}
+		})
+	}
+}
+
+func TestValidateStorageContent(t *testing.T) {
+	r := &resourceNodeStorageContent{}
+	importType := testAttributeType(t, r, "import")
+	vztmplType := testAttributeType(t, r, "vztmpl")
+	image := testObject(t, importType, map[string]tftypes.Value{
+		"url": tftypes.NewValue(tftypes.String, "https://example.com/debian.qcow2"),
+	})
+	snippet := testObject(t, testAttributeType(t, r, "snippet"), map[string]tftypes.Value{
+		"content": tftypes.NewValue(tftypes.String, "#cloud-config\n"),
+	})
+	appliance := func(name interface{}) tftypes.Value {
+		return testObject(t, vztmplType, map[string]tftypes.Value{
+			"appliance": tftypes.NewValue(tftypes.String, name),
+		})
+	}
+	tests := []struct {
+		name     string
+		filename tftypes.Value
+		values   map[string]tftypes.Value
+		wantErr  bool
+	}{
+		{name: "qcow2 image", filename: tftypes.NewValue(tftypes.String, "debian.qcow2"), values: map[string]tftypes.Value{"import": image}},
+		{name: "raw image", filename: tftypes.NewValue(tftypes.String, "debian.raw"), values: map[string]tftypes.Value{"import": image}},
+		{name: "img image", filename: tftypes.NewValue(tftypes.String, "debian.img"), values: map[string]tftypes.Value{"import": image}},
+		{name: "vmdk image", filename: tftypes.NewValue(tftypes.String, "debian.vmdk"), values: map[string]tftypes.Value{"import": image}},
+		{name: "compressed image name", filename: tftypes.NewValue(tftypes.String, "debian.qcow2.xz"), values: map[string]tftypes.Value{"import": image}, wantErr: true},
+		{name: "iso name for an image", filename: tftypes.NewValue(tftypes.String, "debian.iso"), values: map[string]tftypes.Value{"import": image}, wantErr: true},
+		{name: "unknown image name", filename: tftypes.NewValue(tftypes.String, tftypes.UnknownValue), values: map[string]tftypes.Value{"import": image}},
+		{name: "iso name without an image", filename: tftypes.NewValue(tftypes.String, "debian.iso")},
+		{name: "matching appliance", filename: tftypes.NewValue(tftypes.String, "debian-12.tar.zst"), values: map[string]tftypes.Value{"vztmpl": appliance("debian-12.tar.zst")}},
+		{name: "renamed appliance", filename: tftypes.NewValue(tftypes.String, "debian.tar.zst"), values: map[string]tftypes.Value{"vztmpl": appliance("debian-12.tar.zst")}, wantErr: true},
+		{name: "snippet", filename: tftypes.NewValue(tftypes.String, "user-data.yaml"), values: map[string]tftypes.Value{"snippet": snippet}},
+		{name: "snippet in a directory", filename: tftypes.NewValue(tftypes.String, "cloud/user-data.yaml"), values: map[string]tftypes.Value{"snippet": snippet}, wantErr: true},
+		{name: "hidden snippet", filename: tftypes.NewValue(tftypes.String, ".user-data.yaml"), values: map[string]tftypes.Value{"snippet": snippet}, wantErr: true},
+		{name: "unknown appliance", filename: tftypes.NewValue(tftypes.String, "debian.tar.zst"), values: map[string]tftypes.Value{"vztmpl": appliance(tftypes.UnknownValue)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]tftypes.Value{"filename": tt.filename}
+			for k, v := range tt.values {
+				values[k] = v
+			}
+			req := resource.ValidateConfigRequest{Config: testConfig(t, r, values)}
+			resp := &resource.ValidateConfigResponse{}
+			r.ValidateConfig(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Errorf("errors = %v, want error %t", resp.Diagnostics, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStorageContentSchema(t *testing.T) {
+	resp := &resource.SchemaResponse{}
+	(&resourceNodeStorageContent{}).Schema(context.Background(), resource.SchemaRequest{}, resp)
+
+	// Proxmox only supports notes and protection on backups
+	for _, name := range []string{"notes", "protected"} {
+		a := resp.Schema.Attributes[name]
+		if !a.IsComputed() || a.IsOptional() || a.IsRequired() {
+			t.Errorf("%s must be computed only", name)
+		}
+	}
+
+	// Snippets are written again in place
+	snippet := resp.Schema.Blocks["snippet"].(schema.SingleNestedBlock)
+	for _, name := range []string{"content", "path"} {
+		if modifiers := snippet.Attributes[name].(schema.StringAttribute).PlanModifiers; len(modifiers) != 0 {
+			t.Errorf("snippet.%s must not replace the snippet, got %d plan modifiers", name, len(modifiers))
+		}
+	}
+	if a := snippet.Attributes["checksum"]; !a.IsComputed() || a.IsOptional() {
+		t.Error("snippet.checksum must be computed only")
+	}
+
+	// Every download source is recorded in place after an import
+	want := map[string][]string{
+		"iso":    {"url", "checksum", "checksum_algorithm"},
+		"import": {"url", "checksum", "checksum_algorithm", "decompression"},
+		"vztmpl": {"url", "appliance", "checksum", "checksum_algorithm"},
+	}
+	description := requiresReplaceUnlessImportedString().Description(context.Background())
+	for block, attributes := range want {
+		nested := resp.Schema.Blocks[block].(schema.SingleNestedBlock)
+		for _, name := range attributes {
+			found := false
+			for _, m := range nested.Attributes[name].(schema.StringAttribute).PlanModifiers {
+				if m.Description(context.Background()) == description {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("%s.%s must only skip the replacement on import", block, name)
+			}
+		}
+	}
+}
+
+func TestSnippetChecksum(t *testing.T) {
+	content := "#cloud-config\npackages: [vim]\n"
+	sum := sha256.Sum256([]byte(content))
+	want := hex.EncodeToString(sum[:])
+
+	got, err := snippetChecksum(&SnippetModel{Content: types.StringValue(content), Path: types.StringNull()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("content checksum = %q, want %q", got, want)
+	}
+
+	file := filepath.Join(t.TempDir(), "user-data.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err = snippetChecksum(&SnippetModel{Content: types.StringNull(), Path: types.StringValue(file)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("file checksum = %q, want %q", got, want)
+	}
+
+	if _, err := snippetChecksum(&SnippetModel{Content: types.StringNull(), Path: types.StringValue(file + ".missing")}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestPlanSnippet(t *testing.T) {
+	r := &resourceNodeStorageContent{}
+	snippetType := testAttributeType(t, r, "snippet")
+	snippet := func(values map[string]tftypes.Value) tftypes.Value {
+		return testObject(t, snippetType, values)
+	}
+	content := "#cloud-config\n"
+	sum, err := snippetChecksum(&SnippetModel{Content: types.StringValue(content), Path: types.StringNull()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		content     tftypes.Value
+		state       *string
+		wantSum     types.String
+		wantChanged bool
+	}{
+		{name: "create", content: tftypes.NewValue(tftypes.String, content), wantSum: types.StringValue(sum)},
+		{name: "unchanged", content: tftypes.NewValue(tftypes.String, content), state: &sum, wantSum: types.StringValue(sum)},
+		{name: "changed", content: tftypes.NewValue(tftypes.String, content), state: proxmox.String("outdated"), wantSum: types.StringValue(sum), wantChanged: true},
+		{name: "unknown content", content: tftypes.NewValue(tftypes.String, tftypes.UnknownValue), state: &sum, wantSum: types.StringUnknown(), wantChanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := testConfig(t, r, map[string]tftypes.Value{
+				"filename":   tftypes.NewValue(tftypes.String, "user-data.yaml"),
+				"size_bytes": tftypes.NewValue(tftypes.Number, 14),
+				"snippet": snippet(map[string]tftypes.Value{
+					"content": tt.content,
+				}),
+			})
+			req := resource.ModifyPlanRequest{
+				Plan:  tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw},
+				State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Raw.Type(), nil)},
+			}
+			if tt.state != nil {
+				state := testConfig(t, r, map[string]tftypes.Value{
+					"filename":   tftypes.NewValue(tftypes.String, "user-data.yaml"),
+					"size_bytes": tftypes.NewValue(tftypes.Number, 14),
+					"snippet": snippet(map[string]tftypes.Value{
+						"content":  tftypes.NewValue(tftypes.String, content),
+						"checksum": tftypes.NewValue(tftypes.String, *tt.state),
+					}),
+				})
+				req.State.Raw = state.Raw
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+
+			model := &SnippetModel{}
+			req.Plan.GetAttribute(context.Background(), path.Root("snippet"), model)
+			diags := planSnippet(context.Background(), req, resp, model)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if len(resp.RequiresReplace) != 0 {
+				t.Errorf("RequiresReplace = %v, snippets are written again in place", resp.RequiresReplace)
+			}
+
+			var got types.String
+			resp.Plan.GetAttribute(context.Background(), path.Root("snippet").AtName("checksum"), &got)
+			if !got.Equal(tt.wantSum) {
+				t.Errorf("planned checksum = %v, want %v", got, tt.wantSum)
+			}
+			var size types.Int64
+			resp.Plan.GetAttribute(context.Background(), path.Root("size_bytes"), &size)
+			if size.IsUnknown() != tt.wantChanged {
+				t.Errorf("planned size_bytes = %v, want unknown %t", size, tt.wantChanged)
+			}
+		})
+	}
+}
@@ -2,30 +2,63 @@ package proxmox
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 
 	"github.com/FreekingDean/proxmox-api-go/proxmox"
+	"github.com/FreekingDean/proxmox-api-go/proxmox/cluster"
 	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/qemu"
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/qemu/agent"
 	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/qemu/status"
+	"github.com/FreekingDean/proxmox-api-go/proxmox/pools"
 
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/apierr"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/retry"
 	"github.com/FreekingDean/terraform-provider-proxmox/internal/tasks"
 )
 
+const (
+	maxUSBDevices    = 14
+	maxSerialDevices = 4
+)
+
+var (
+	bootDeviceRegex   = regexp.MustCompile(`^(ide|scsi|net|hostpci)[0-9]+$`)
+	tagRegex          = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_\-\+\.]*$`)
+	usbIDRegex        = regexp.MustCompile(`^([0-9a-fA-F]{4}:[0-9a-fA-F]{4}|spice)$`)
+	usbPortRegex      = regexp.MustCompile(`^[0-9]+-[0-9]+(\.[0-9]+)*$`)
+	serialDeviceRegex = regexp.MustCompile(`^(socket|/dev/.+)$`)
+	uuidRegex         = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	machineRegex      = regexp.MustCompile(`^(pc|q35|pc-(i440fx-)?[0-9]+(\.[0-9]+)+(\+pve[0-9]+)?(\.pxe)?|pc-q35-[0-9]+(\.[0-9]+)+(\+pve[0-9]+)?(\.pxe)?|virt(-[0-9]+(\.[0-9]+)+)?(\+pve[0-9]+)?)$`)
+)
+
 // Type scsi,ide
 // Media cdrom,disk
 type Disk struct {
@@ -43,31 +76,120 @@ type Network struct {
 	Firewall types.Bool   `tfsdk:"firewall"`
 }
 
+type HostPCI struct {
+	ID      types.String `tfsdk:"id"`
+	Mapping types.String `tfsdk:"mapping"`
+	PCIE    types.Bool   `tfsdk:"pcie"`
+	ROMBar  types.Bool   `tfsdk:"rombar"`
+	XVGA    types.Bool   `tfsdk:"x_vga"`
+	MDev    types.String `tfsdk:"mdev"`
+}
+
+type USB struct {
+	ID      types.String `tfsdk:"id"`
+	Port    types.String `tfsdk:"port"`
+	Mapping types.String `tfsdk:"mapping"`
+	USB3    types.Bool   `tfsdk:"usb3"`
+}
+
+type Serial struct {
+	Device types.String `tfsdk:"device"`
+}
+
+type VGA struct {
+	Type      types.String `tfsdk:"type"`
+	Memory    types.Int64  `tfsdk:"memory"`
+	Clipboard types.String `tfsdk:"clipboard"`
+}
+
+type SMBIOS struct {
+	UUID         types.String `tfsdk:"uuid"`
+	Manufacturer types.String `tfsdk:"manufacturer"`
+	Product      types.String `tfsdk:"product"`
+	Serial       types.String `tfsdk:"serial"`
+	SKU          types.String `tfsdk:"sku"`
+	Family       types.String `tfsdk:"family"`
+}
+
+type Agent struct {
+	Enabled           types.Bool   `tfsdk:"enabled"`
+	Type              types.String `tfsdk:"type"`
+	FstrimClonedDisks types.Bool   `tfsdk:"fstrim_cloned_disks"`
+	FreezeFSOnBackup  types.Bool   `tfsdk:"freeze_fs_on_backup"`
+}
+
+type Startup struct {
+	Order types.Int64 `tfsdk:"order"`
+	Up    types.Int64 `tfsdk:"up"`
+	Down  types.Int64 `tfsdk:"down"`
+}
+
+type NetworkInterface struct {
+	Name          types.String   `tfsdk:"name"`
+	MACAddress    types.String   `tfsdk:"mac_address"`
+	IPv4Addresses []types.String `tfsdk:"ipv4_addresses"`
+	IPv6Addresses []types.String `tfsdk:"ipv6_addresses"`
+}
+
+var networkInterfaceType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"name":           types.StringType,
+		"mac_address":    types.StringType,
+		"ipv4_addresses": types.ListType{ElemType: types.StringType},
+		"ipv6_addresses": types.ListType{ElemType: types.StringType},
+	},
+}
+
 type resourceNodeVirtualMachineModel struct {
-	ID         types.Int64    `tfsdk:"id"`
-	Name       types.String   `tfsdk:"name"`
-	Reboot     types.Bool     `tfsdk:"reboot"`
-	FWConfig   types.String   `tfsdk:"fw_config"`
-	GuestAgent types.Bool     `tfsdk:"guest_agent"`
-	Node       types.String   `tfsdk:"node"`
-	Ides       []*Disk        `tfsdk:"ide"`
-	Scsis      []*Disk        `tfsdk:"scsi"`
-	Networks   []*Network     `tfsdk:"network"`
-	Memory     types.Int64    `tfsdk:"memory"`
-	CPUs       types.Int64    `tfsdk:"cpus"`
-	Serials    []types.String `tfsdk:"serials"`
+	ID        types.Int64    `tfsdk:"id"`
+	Name      types.String   `tfsdk:"name"`
+	Reboot    types.Bool     `tfsdk:"reboot"`
+	FWConfig  types.String   `tfsdk:"fw_config"`
+	Args      []types.String `tfsdk:"args"`
+	Agent     *Agent         `tfsdk:"agent"`
+	Node      types.String   `tfsdk:"node"`
+	Ides      []*Disk        `tfsdk:"ide"`
+	Scsis     []*Disk        `tfsdk:"scsi"`
+	Networks  []*Network     `tfsdk:"network"`
+	HostPCIs  []*HostPCI     `tfsdk:"hostpci"`
+	USBs      []*USB         `tfsdk:"usb"`
+	Memory    types.Int64    `tfsdk:"memory"`
+	CPUs      types.Int64    `tfsdk:"cpus"`
+	Serials   []*Serial      `tfsdk:"serial"`
+	VGA       *VGA           `tfsdk:"vga"`
+	BootOrder []types.String `tfsdk:"boot_order"`
+	OnBoot    types.Bool     `tfsdk:"on_boot"`
+	Startup   *Startup       `tfsdk:"startup"`
+	Tags      []types.String `tfsdk:"tags"`
+	Desc      types.String   `tfsdk:"description"`
+	Pool      types.String   `tfsdk:"pool"`
+	Template  types.Bool     `tfsdk:"is_template"`
+	SMBIOS    *SMBIOS        `tfsdk:"smbios"`
+	Machine   types.String   `tfsdk:"machine"`
+	OSType    types.String   `tfsdk:"ostype"`
+
+	GuestAgentTimeout types.Int64 `tfsdk:"guest_agent_timeout"`
+	IPv4Addresses     types.List  `tfsdk:"ipv4_addresses"`
+	IPv6Addresses     types.List  `tfsdk:"ipv6_addresses"`
+	NetworkInterfaces types.List  `tfsdk:"network_interfaces"`
 }
 
 type resourceNodeVirtualMachine struct {
-	t *tasks.Client
-	q *qemu.Client
-	c *status.Client
+	t  *tasks.Client
+	q  *qemuClient
+	c  *status.Client
+	a  *agent.Client
+	p  *pools.Client
+	cl *cluster.Client
 }
 
-func (r *resourceNodeVirtualMachine) SetClient(p *proxmox.Client) {
+func (r *resourceNodeVirtualMachine) SetClient(p apiClient) {
 	r.t = tasks.New(p)
-	r.q = qemu.New(p)
+	r.q = newQemuClient(p)
 	r.c = status.New(p)
+	r.a = agent.New(p)
+	r.p = pools.New(p)
+	r.cl = cluster.New(p)
 }
 
 func (r *resourceNodeVirtualMachine) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -128,13 +250,14 @@ func (e *resourceNodeVirtualMachine) Schema(ctx context.Context, req resource.Sc
 					},
 					"backup": schema.BoolAttribute{
 						Optional:    true,
-						Description: "If the disk should be backed up during backup",
+						Description: "If the disk should be backed up during backup (default: true)",
 					},
 				},
 			},
 		}
 	}
 	resp.Schema = schema.Schema{
+		Version: 2,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.Int64Attribute{
 				Required:    true,
@@ -164,7 +287,50 @@ func (e *resourceNodeVirtualMachine) Schema(ctx context.Context, req resource.Sc
 			},
 			"fw_config": schema.StringAttribute{
 				Optional:    true,
-				Description: "Additional arguments to pass to qemu",
+				Description: "A -fw_cfg entry to pass to qemu (i.e. name=opt/com.example/config,string=value), further entries can be added to args",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"args": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Additional arguments to pass to qemu, one argument per element (i.e. [\"-cpu\", \"host,+aes\"]), quoting is handled automatically",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"machine": schema.StringAttribute{
+				Optional:    true,
+				Description: "The qemu machine type, optionally pinned to a version (i.e. q35, pc-i440fx-8.1, pc-q35-7.2+pve0)",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(machineRegex, "must be a valid qemu machine type"),
+				},
+			},
+			"ostype": schema.StringAttribute{
+				Optional:    true,
+				Description: "The guest operating system type (i.e. l26, win11, other)",
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						string(qemu.Ostype_OTHER),
+						string(qemu.Ostype_WXP),
+						string(qemu.Ostype_W2K),
+						string(qemu.Ostype_W2K3),
+						string(qemu.Ostype_W2K8),
+						string(qemu.Ostype_WVISTA),
+						string(qemu.Ostype_WIN7),
+						string(qemu.Ostype_WIN8),
+						string(qemu.Ostype_WIN10),
+						string(qemu.Ostype_WIN11),
+						string(qemu.Ostype_L24),
+						string(qemu.Ostype_L26),
+						string(qemu.Ostype_SOLARIS),
+					),
+				},
+			},
+			"is_template": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Convert the VM into a template, a template can not be converted back into a VM",
 			},
 			"reboot": schema.BoolAttribute{
 				Optional:    true,
@@ -173,14 +339,90 @@ func (e *resourceNodeVirtualMachine) Schema(ctx context.Context, req resource.Sc
 					boolplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"serials": schema.ListAttribute{
+			"guest_agent_timeout": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Seconds to wait for the guest agent to report network addresses on create and update, refresh queries the agent once (default: 0)",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"ipv4_addresses": schema.ListAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "The non loopback IPv4 addresses reported by the guest agent",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ipv6_addresses": schema.ListAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "The non loopback, non link-local IPv6 addresses reported by the guest agent",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"network_interfaces": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The network interfaces reported by the guest agent",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "The interface name inside the guest",
+						},
+						"mac_address": schema.StringAttribute{
+							Computed:    true,
+							Description: "The hardware address of the interface",
+						},
+						"ipv4_addresses": schema.ListAttribute{
+							ElementType: types.StringType,
+							Computed:    true,
+							Description: "The IPv4 addresses on the interface",
+						},
+						"ipv6_addresses": schema.ListAttribute{
+							ElementType: types.StringType,
+							Computed:    true,
+							Description: "The IPv6 addresses on the interface",
+						},
+					},
+				},
+			},
+			"boot_order": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The devices to boot from in order (i.e. scsi0, ide2, net0)",
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(
+						stringvalidator.RegexMatches(bootDeviceRegex, "must be a disk, network or hostpci device key"),
+					),
+				},
+			},
+			"on_boot": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Start the VM when the node boots",
+			},
+			"tags": schema.SetAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
-				Description: "A list (max 3) of serial devices on the guest",
+				Description: "Tags to apply to the VM",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(
+						stringvalidator.RegexMatches(tagRegex, "must only contain letters, numbers and _ - + ."),
+					),
+				},
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description (markdown) shown in the VM's summary",
 			},
-			"guest_agent": schema.BoolAttribute{
+			"pool": schema.StringAttribute{
 				Optional:    true,
-				Description: "Enables the guest agent on the VM",
+				Description: "The resource pool the VM belongs to",
 			},
 		},
 		Blocks: map[string]schema.Block{
@@ -201,119 +443,634 @@ func (e *resourceNodeVirtualMachine) Schema(ctx context.Context, req resource.Sc
 					},
 				},
 			},
+			"serial": schema.ListNestedBlock{
+				Description: "A serial device on the guest (max 4)",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(maxSerialDevices),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"device": schema.StringAttribute{
+							Required:    true,
+							Description: "Either 'socket' to create a unix socket or a host serial device path (i.e. /dev/ttyS0)",
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(serialDeviceRegex, "must be 'socket' or a /dev/ path"),
+							},
+						},
+					},
+				},
+			},
+			"smbios": schema.SingleNestedBlock{
+				Description: "The SMBIOS (type 1) system information presented to the guest",
+				Attributes: map[string]schema.Attribute{
+					"uuid": schema.StringAttribute{
+						Optional:    true,
+						Computed:    true,
+						Description: "The system UUID, a random UUID is generated if unset",
+						Validators: []validator.String{
+							stringvalidator.RegexMatches(uuidRegex, "must be a valid UUID"),
+						},
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.UseStateForUnknown(),
+						},
+					},
+					"manufacturer": schema.StringAttribute{
+						Optional:    true,
+						Description: "The system manufacturer",
+					},
+					"product": schema.StringAttribute{
+						Optional:    true,
+						Description: "The product name",
+					},
+					"serial": schema.StringAttribute{
+						Optional:    true,
+						Description: "The system serial number",
+					},
+					"sku": schema.StringAttribute{
+						Optional:    true,
+						Description: "The SKU number",
+					},
+					"family": schema.StringAttribute{
+						Optional:    true,
+						Description: "The system family",
+					},
+				},
+			},
+			"vga": schema.SingleNestedBlock{
+				Description: "The display configuration",
+				Attributes: map[string]schema.Attribute{
+					"type": schema.StringAttribute{
+						Optional:    true,
+						Description: "The display type (i.e. std, virtio, qxl, serial0, none)",
+						Validators: []validator.String{
+							stringvalidator.OneOf(
+								"cirrus", "none", "qxl", "qxl2", "qxl3", "qxl4",
+								"serial0", "serial1", "serial2", "serial3",
+								"std", "virtio", "virtio-gl", "vmware",
+							),
+						},
+					},
+					"memory": schema.Int64Attribute{
+						Optional:    true,
+						Description: "The display memory in MB",
+						Validators: []validator.Int64{
+							int64validator.Between(4, 512),
+						},
+					},
+					"clipboard": schema.StringAttribute{
+						Optional:    true,
+						Description: "Enable a clipboard, only vnc is supported",
+						Validators: []validator.String{
+							stringvalidator.OneOf("vnc"),
+						},
+					},
+				},
+			},
+			"agent": schema.SingleNestedBlock{
+				Description: "The qemu guest agent configuration",
+				Attributes: map[string]schema.Attribute{
+					"enabled": schema.BoolAttribute{
+						Required:    true,
+						Description: "Enables the guest agent on the VM",
+					},
+					"type": schema.StringAttribute{
+						Optional:    true,
+						Description: "The agent device type (virtio or isa)",
+						Validators: []validator.String{
+							stringvalidator.OneOf(
+								string(qemu.AgentType_VIRTIO),
+								string(qemu.AgentType_ISA),
+							),
+						},
+					},
+					"fstrim_cloned_disks": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Run fstrim after moving a disk or migrating the VM",
+					},
+					"freeze_fs_on_backup": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Freeze guest filesystems during backups for consistency (default: true)",
+					},
+				},
+			},
+			"startup": schema.SingleNestedBlock{
+				Description: "Startup and shutdown ordering relative to other guests on the node, at least one attribute must be set",
+				Attributes: map[string]schema.Attribute{
+					"order": schema.Int64Attribute{
+						Optional:    true,
+						Description: "The startup order, shutdown happens in reverse order",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+					"up": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Delay in seconds to wait before starting the next guest",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+					"down": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Timeout in seconds to wait for this guest to shutdown",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+					},
+				},
+			},
+			"hostpci": schema.ListNestedBlock{
+				Description: "A host PCI device to pass through to the guest",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(16),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Optional:    true,
+							Description: "The host PCI ID (i.e. 0000:01:00.0), multiple IDs can be separated by ;",
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("mapping")),
+							},
+						},
+						"mapping": schema.StringAttribute{
+							Optional:    true,
+							Description: "The cluster PCI resource mapping to use instead of a raw host ID",
+						},
+						"pcie": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Pass the device as a PCI-express device (requires the q35 machine type)",
+						},
+						"rombar": schema.BoolAttribute{
+							Optional:    true,
+							Description: "If the device ROM should be visible in the guest memory map (default: true)",
+						},
+						"x_vga": schema.BoolAttribute{
+							Optional:    true,
+							Description: "Use the device as the primary VGA of the guest",
+						},
+						"mdev": schema.StringAttribute{
+							Optional:    true,
+							Description: "The mediated device type to create (i.e. nvidia-63)",
+						},
+					},
+				},
+			},
+			"usb": schema.ListNestedBlock{
+				Description: "A host USB device to pass through to the guest",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(14),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Optional:    true,
+							Description: "The host USB device as vendor_id:product_id or 'spice' for a spice redirection device",
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(
+									path.MatchRelative().AtParent().AtName("port"),
+									path.MatchRelative().AtParent().AtName("mapping"),
+								),
+								stringvalidator.RegexMatches(usbIDRegex, "must be vendor_id:product_id or spice"),
+							},
+						},
+						"port": schema.StringAttribute{
+							Optional:    true,
+							Description: "The host USB port as bus-port(.port)*",
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(usbPortRegex, "must be in the form bus-port(.port)*"),
+							},
+						},
+						"mapping": schema.StringAttribute{
+							Optional:    true,
+							Description: "The cluster USB resource mapping to use instead of a host device",
+						},
+						"usb3": schema.BoolAttribute{
+							Optional:    true,
+							Description: "If the device or port is USB3",
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
-func (r *resourceNodeVirtualMachine) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var plan resourceNodeVirtualMachineModel
-	diags := req.Plan.Get(ctx, &plan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+func (r *resourceNodeVirtualMachine) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {StateUpgrader: upgradeVirtualMachineState(upgradeGuestAgent, upgradeSerials)},
+		1: {StateUpgrader: upgradeVirtualMachineState(upgradeSerials)},
 	}
+}
 
-	creq := qemu.CreateRequest{
-		Node: plan.Node.ValueString(),
-		Vmid: int(plan.ID.ValueInt64()),
+// upgradeVirtualMachineState returns a state upgrader applying each step to
+// the raw JSON state in order.
+func upgradeVirtualMachineState(steps ...func(map[string]json.RawMessage) error) func(context.Context, resource.UpgradeStateRequest, *resource.UpgradeStateResponse) {
+	return func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+		rawState := map[string]json.RawMessage{}
+		err := json.Unmarshal(req.RawState.JSON, &rawState)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Upgrade State",
+				"An unexpected error occurred when parsing the prior VM state. "+
+					"Error: "+err.Error(),
+			)
+			return
+		}
 
-		Memory: proxmox.Int(int(plan.Memory.ValueInt64())),
-		Cores:  proxmox.Int(int(plan.CPUs.ValueInt64())),
-	}
+		for _, step := range steps {
+			err = step(rawState)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to Upgrade State",
+					"An unexpected error occurred when upgrading the prior VM state. "+
+						"Error: "+err.Error(),
+				)
+				return
+			}
+		}
 
-	if !plan.GuestAgent.IsNull() {
-		creq.Agent = &qemu.Agent{
-			Enabled: *proxmox.PVEBool(plan.GuestAgent.ValueBool()),
+		data, err := json.Marshal(rawState)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Upgrade State",
+				"An unexpected error occurred when building the upgraded VM state. "+
+					"Error: "+err.Error(),
+			)
+			return
+		}
+		resp.DynamicValue = &tfprotov6.DynamicValue{
+			JSON: data,
 		}
 	}
+}
 
-	if plan.Name.ValueString() != "" {
-		creq.Name = proxmox.String(plan.Name.ValueString())
+// upgradeGuestAgent replaces the guest_agent bool with the agent block.
+func upgradeGuestAgent(rawState map[string]json.RawMessage) error {
+	guestAgent, ok := rawState["guest_agent"]
+	if !ok {
+		return nil
 	}
+	delete(rawState, "guest_agent")
 
-	serials := make([]*string, len(plan.Serials))
-	for i, s := range plan.Serials {
-		serialString := s.ValueString()
-		serials[i] = &serialString
-	}
-	if len(serials) > 0 {
-		creq.Serials = (*qemu.Serials)(&serials)
+	var enabled *bool
+	err := json.Unmarshal(guestAgent, &enabled)
+	if err != nil || enabled == nil {
+		return err
 	}
+	rawState["agent"], err = json.Marshal(map[string]interface{}{
+		"enabled":             *enabled,
+		"type":                nil,
+		"fstrim_cloned_disks": nil,
+		"freeze_fs_on_backup": nil,
+	})
+	return err
+}
 
-	if plan.FWConfig.ValueString() != "" {
-		cfgString := "-fw_cfg " + plan.FWConfig.ValueString()
-		creq.Args = &cfgString
+// upgradeSerials replaces the serials string list with serial blocks.
+func upgradeSerials(rawState map[string]json.RawMessage) error {
+	rawSerials, ok := rawState["serials"]
+	if !ok {
+		return nil
 	}
+	delete(rawState, "serials")
 
-	nets := make(qemu.Nets, len(plan.Networks))
-	for i, net := range plan.Networks {
-		nets[i] = &qemu.Net{
-			Firewall: proxmox.PVEBool(net.Firewall.ValueBool()),
-			Bridge:   proxmox.String(net.Bridge.ValueString()),
-			Model:    qemu.NetModel_VIRTIO,
-		}
+	serials := []*string{}
+	err := json.Unmarshal(rawSerials, &serials)
+	if err != nil {
+		return err
 	}
-	creq.Nets = &nets
-
-	if len(plan.Ides) > 0 {
-		ideArr := make(qemu.Ides, len(plan.Ides))
-		for i, d := range plan.Ides {
-			ide := &qemu.Ide{}
-			proxmoxDisk(d, (*wrappedIde)(ide))
-			ideArr[i] = ide
-		}
-		creq.Ides = &ideArr
+	blocks := make([]map[string]interface{}, len(serials))
+	for i, serial := range serials {
+		blocks[i] = map[string]interface{}{"device": serial}
 	}
+	rawState["serial"], err = json.Marshal(blocks)
+	return err
+}
 
-	if len(plan.Scsis) > 0 {
-		scsiArr := make(qemu.Scsis, len(plan.Scsis))
-		for i, d := range plan.Scsis {
-			scsi := &qemu.Scsi{}
-			proxmoxDisk(d, (*wrappedScsi)(scsi))
-			scsiArr[i] = scsi
-		}
-		creq.Scsis = &scsiArr
+func (r *resourceNodeVirtualMachine) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var template, reboot types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("is_template"), &template)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("reboot"), &reboot)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	task, err := r.q.Create(ctx, creq)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating VM",
-			"An unexpected error occurred when creating the VM. "+
-				"Proxmox API Error: "+err.Error(),
+	if template.ValueBool() && reboot.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("reboot"),
+			"Invalid reboot on template",
+			"A template can not be started, reboot must not be set when is_template is true.",
 		)
-		return
 	}
 
-	diags = r.t.Wait(ctx, task, plan.Node.ValueString())
-	resp.Diagnostics.Append(diags...)
+	var vgaType types.String
+	var serials types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("vga").AtName("type"), &vgaType)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("serial"), &serials)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	if strings.HasPrefix(vgaType.ValueString(), "serial") && !serials.IsUnknown() {
+		index, _ := strconv.Atoi(strings.TrimPrefix(vgaType.ValueString(), "serial"))
+		if len(serials.Elements()) <= index {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("vga").AtName("type"),
+				"Missing serial device",
+				fmt.Sprintf("The display type %q requires a serial block at index %d.", vgaType.ValueString(), index),
+			)
+		}
+	}
 
-	config, err := r.q.VmConfig(ctx, qemu.VmConfigRequest{
-		Node: plan.Node.ValueString(),
-		Vmid: int(plan.ID.ValueInt64()),
-	})
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error gettng  VM config",
-			"An unexpected error occurred when retreiving the VM config. "+
-				"Proxmox API Error: "+err.Error(),
+	// An empty startup block is not stored by proxmox and would never match
+	var startup types.Object
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("startup"), &startup)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	emptyStartup := !startup.IsNull() && !startup.IsUnknown()
+	for _, v := range startup.Attributes() {
+		if !v.IsNull() {
+			emptyStartup = false
+		}
+	}
+	if emptyStartup {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("startup"),
+			"Empty startup block",
+			"At least one of order, up or down must be set in the startup block.",
 		)
+	}
+
+	var bootOrder types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("boot_order"), &bootOrder)...)
+	if resp.Diagnostics.HasError() || bootOrder.IsNull() || bootOrder.IsUnknown() {
 		return
 	}
-	for i, d := range plan.Scsis {
-		if config.Scsis == nil || len(*config.Scsis) <= i {
-			resp.Diagnostics.AddError(
-				"Not enough disks",
-				"Something went wrong creating the VM not enough scsi Disks",
-			)
+
+	devices := map[string]bool{}
+	for block, key := range map[string]string{
+		"ide":     "ide",
+		"scsi":    "scsi",
+		"network": "net",
+		"hostpci": "hostpci",
+	} {
+		var list types.List
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(block), &list)...)
+		if resp.Diagnostics.HasError() || list.IsUnknown() {
 			return
 		}
-		d.VolumeID = types.StringValue((*config.Scsis)[i].File)
+		for i := range list.Elements() {
+			devices[fmt.Sprintf("%s%d", key, i)] = true
+		}
 	}
-	for i, d := range plan.Ides {
+
+	for i, elem := range bootOrder.Elements() {
+		device, ok := elem.(types.String)
+		if !ok || device.IsNull() || device.IsUnknown() {
+			continue
+		}
+		if !devices[device.ValueString()] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("boot_order").AtListIndex(i),
+				"Unknown boot device",
+				fmt.Sprintf("The boot device %q does not match any configured ide, scsi, network or hostpci block.", device.ValueString()),
+			)
+		}
+	}
+}
+
+func (r *resourceNodeVirtualMachine) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state resourceNodeVirtualMachineModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !state.Template.ValueBool() {
+		// Converting to a template renames the disks to base images
+		if plan.Template.ValueBool() {
+			for _, d := range append(plan.Ides, plan.Scsis...) {
+				d.VolumeID = types.StringUnknown()
+			}
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		}
+		return
+	}
+
+	if !plan.Template.IsUnknown() && !plan.Template.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("is_template"),
+			"Template can not be converted to a VM",
+			"Proxmox does not support converting a template back into a VM, "+
+				"clone the template or recreate the resource instead.",
+		)
+	}
+
+	// The disks of a template are base images which can not be replaced or
+	// removed, new disks can still be added.
+	for _, disks := range []struct {
+		block string
+		state []*Disk
+		plan  []*Disk
+	}{
+		{"ide", state.Ides, plan.Ides},
+		{"scsi", state.Scsis, plan.Scsis},
+	} {
+		for i, d := range disks.state {
+			if len(disks.plan) <= i {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(disks.block).AtListIndex(i),
+					"Template disk can not be removed",
+					fmt.Sprintf("The %s%d disk of a template is a base image and can not be removed.", disks.block, i),
+				)
+			} else if !d.Equal(disks.plan[i]) {
+				resp.Diagnostics.AddAttributeError(
+					path.Root(disks.block).AtListIndex(i),
+					"Template disk can not be changed",
+					fmt.Sprintf("The %s%d disk of a template is a base image and can not be changed.", disks.block, i),
+				)
+			}
+		}
+	}
+}
+
+func (r *resourceNodeVirtualMachine) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourceNodeVirtualMachineModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	creq := qemu.CreateRequest{
+		Node: plan.Node.ValueString(),
+		Vmid: int(plan.ID.ValueInt64()),
+
+		Memory: proxmox.Int(int(plan.Memory.ValueInt64())),
+		Cores:  proxmox.Int(int(plan.CPUs.ValueInt64())),
+	}
+
+	if plan.Name.ValueString() != "" {
+		creq.Name = proxmox.String(plan.Name.ValueString())
+	}
+
+	if len(plan.Serials) > 0 {
+		serials := make(qemu.Serials, len(plan.Serials))
+		for i, serial := range plan.Serials {
+			serials[i] = proxmox.String(serial.Device.ValueString())
+		}
+		creq.Serials = &serials
+	}
+
+	if args := argsString(plan.FWConfig, plan.Args); args != "" {
+		creq.Args = proxmox.String(args)
+	}
+
+	if plan.SMBIOS != nil {
+		// Proxmox only generates a UUID when smbios1 is not sent at all
+		if plan.SMBIOS.UUID.ValueString() == "" {
+			plan.SMBIOS.UUID = types.StringValue(uuid.NewString())
+		}
+		creq.Smbios1 = proxmox.String(plan.SMBIOS.String())
+	}
+
+	if plan.Machine.ValueString() != "" {
+		creq.Machine = proxmox.String(plan.Machine.ValueString())
+	}
+
+	if plan.OSType.ValueString() != "" {
+		creq.Ostype = qemu.PtrOstype(qemu.Ostype(plan.OSType.ValueString()))
+	}
+
+	if len(plan.BootOrder) > 0 {
+		creq.Boot = proxmox.String(bootString(plan.BootOrder))
+	}
+
+	if !plan.OnBoot.IsNull() {
+		creq.Onboot = proxmox.PVEBool(plan.OnBoot.ValueBool())
+	}
+
+	if plan.Startup != nil {
+		creq.Startup = proxmox.String(plan.Startup.String())
+	}
+
+	if len(plan.Tags) > 0 {
+		creq.Tags = proxmox.String(tagsString(plan.Tags))
+	}
+
+	if plan.Desc.ValueString() != "" {
+		creq.Description = proxmox.String(plan.Desc.ValueString())
+	}
+
+	if plan.Pool.ValueString() != "" {
+		creq.Pool = proxmox.String(plan.Pool.ValueString())
+	}
+
+	nets := make(qemu.Nets, len(plan.Networks))
+	for i, net := range plan.Networks {
+		nets[i] = &qemu.Net{
+			Firewall: proxmox.PVEBool(net.Firewall.ValueBool()),
+			Bridge:   proxmox.String(net.Bridge.ValueString()),
+			Model:    qemu.NetModel_VIRTIO,
+		}
+	}
+	creq.Nets = &nets
+
+	if len(plan.Ides) > 0 {
+		ideArr := make(qemu.Ides, len(plan.Ides))
+		for i, d := range plan.Ides {
+			ide := &qemu.Ide{}
+			proxmoxDisk(d, (*wrappedIde)(ide))
+			ideArr[i] = ide
+		}
+		creq.Ides = &ideArr
+	}
+
+	if len(plan.Scsis) > 0 {
+		scsiArr := make(qemu.Scsis, len(plan.Scsis))
+		for i, d := range plan.Scsis {
+			scsi := &qemu.Scsi{}
+			proxmoxDisk(d, (*wrappedScsi)(scsi))
+			scsiArr[i] = scsi
+		}
+		creq.Scsis = &scsiArr
+	}
+
+	if len(plan.HostPCIs) > 0 {
+		hostpcis := make(qemu.Hostpcis, len(plan.HostPCIs))
+		for i, h := range plan.HostPCIs {
+			hostpcis[i] = proxmox.String(h.String())
+		}
+		creq.Hostpcis = &hostpcis
+	}
+
+	raw := rawConfig{}
+	for i, u := range plan.USBs {
+		raw[fmt.Sprintf("usb%d", i)] = u.String()
+	}
+
+	if plan.Agent != nil {
+		raw["agent"] = plan.Agent.String()
+	}
+
+	if plan.VGA != nil {
+		raw["vga"] = plan.VGA.String()
+	}
+
+	err := retry.OnLock(ctx, "create VM", func() error {
+		task, err := r.q.CreateWithRaw(ctx, creq, raw)
+		if err != nil {
+			return err
+		}
+		return r.t.WaitForExit(ctx, task, plan.Node.ValueString())
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating VM",
+			"An unexpected error occurred when creating the VM. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+
+	if plan.Template.ValueBool() {
+		resp.Diagnostics.Append(r.convertToTemplate(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	config, err := r.q.VmConfig(ctx, qemu.VmConfigRequest{
+		Node: plan.Node.ValueString(),
+		Vmid: int(plan.ID.ValueInt64()),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error gettng  VM config",
+			"An unexpected error occurred when retreiving the VM config. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+	if plan.SMBIOS != nil {
+		plan.SMBIOS.UUID = smbiosUUID(config.Smbios1)
+	}
+	for i, d := range plan.Scsis {
+		if config.Scsis == nil || len(*config.Scsis) <= i {
+			resp.Diagnostics.AddError(
+				"Not enough disks",
+				"Something went wrong creating the VM not enough scsi Disks",
+			)
+			return
+		}
+		d.VolumeID = types.StringValue((*config.Scsis)[i].File)
+	}
+	for i, d := range plan.Ides {
 		if config.Ides == nil || len(*config.Ides) <= i {
 			resp.Diagnostics.AddError(
 				"Not enough disks",
@@ -324,6 +1081,11 @@ func (r *resourceNodeVirtualMachine) Create(ctx context.Context, req resource.Cr
 		d.VolumeID = types.StringValue((*config.Ides)[i].File)
 	}
 
+	resp.Diagnostics.Append(r.readGuestNetwork(ctx, &plan, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -339,13 +1101,19 @@ func (r *resourceNodeVirtualMachine) Delete(ctx context.Context, req resource.De
 		return
 	}
 
-	taskID, err := r.q.Delete(ctx, qemu.DeleteRequest{
-		Node:  data.Node.ValueString(),
-		Vmid:  int(data.ID.ValueInt64()),
-		Purge: proxmox.PVEBool(true),
+	err := retry.OnLock(ctx, "delete VM", func() error {
+		taskID, err := r.q.Delete(ctx, qemu.DeleteRequest{
+			Node:  data.Node.ValueString(),
+			Vmid:  int(data.ID.ValueInt64()),
+			Purge: proxmox.PVEBool(true),
+		})
+		if err != nil {
+			return err
+		}
+		return r.t.WaitForExit(ctx, taskID, data.Node.ValueString())
 	})
 	if err != nil {
-		if err.Error() == fmt.Sprintf("non 200: 500 Configuration file 'nodes/%s/qemu-server/%d.conf' does not exist", data.Node.ValueString(), data.ID.ValueInt64()) {
+		if apierr.Is(err, apierr.ErrGuestNotFound) {
 			return
 		}
 		resp.Diagnostics.AddError(
@@ -355,11 +1123,6 @@ func (r *resourceNodeVirtualMachine) Delete(ctx context.Context, req resource.De
 		)
 		return
 	}
-	diags := r.t.Wait(ctx, taskID, data.Node.ValueString())
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
 }
 
 func (r *resourceNodeVirtualMachine) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -382,10 +1145,6 @@ func (r *resourceNodeVirtualMachine) Update(ctx context.Context, req resource.Up
 		Vmid: int(plan.ID.ValueInt64()),
 	}
 
-	if plan.Name.ValueString() != "" {
-		configReq.Name = proxmox.String(plan.Name.ValueString())
-	}
-
 	if !plan.Memory.Equal(state.Memory) {
 		configReq.Memory = proxmox.Int(int(plan.Memory.ValueInt64()))
 	}
@@ -395,36 +1154,145 @@ func (r *resourceNodeVirtualMachine) Update(ctx context.Context, req resource.Up
 	}
 
 	toDel := []string{}
+	raw := rawConfig{}
+
+	if !plan.Name.Equal(state.Name) {
+		if plan.Name.ValueString() == "" {
+			toDel = append(toDel, "name")
+		} else {
+			configReq.Name = proxmox.String(plan.Name.ValueString())
+		}
+	}
 
-	if !plan.GuestAgent.Equal(state.GuestAgent) {
-		if plan.GuestAgent.IsNull() {
+	serials := make(qemu.Serials, len(plan.Serials))
+	serialsChanged := false
+	for i, serial := range plan.Serials {
+		if len(state.Serials) <= i || !state.Serials[i].Equal(serial) {
+			serials[i] = proxmox.String(serial.Device.ValueString())
+			serialsChanged = true
+		}
+	}
+	if serialsChanged {
+		configReq.Serials = &serials
+	}
+	for i := len(plan.Serials); i < len(state.Serials); i++ {
+		toDel = append(toDel, fmt.Sprintf("serial%d", i))
+	}
+
+	if !plan.Agent.Equal(state.Agent) {
+		if plan.Agent == nil {
 			toDel = append(toDel, "agent")
 		} else {
-			configReq.Agent = &qemu.Agent{
-				Enabled: *proxmox.PVEBool(plan.GuestAgent.ValueBool()),
+			raw["agent"] = plan.Agent.String()
+		}
+	}
+
+	// Removing the smbios block only stops managing it, deleting smbios1 would
+	// change the UUID of the guest.
+	if plan.SMBIOS != nil && !plan.SMBIOS.Equal(state.SMBIOS) {
+		if plan.SMBIOS.UUID.IsUnknown() {
+			config, err := r.q.VmConfig(ctx, qemu.VmConfigRequest{
+				Node: plan.Node.ValueString(),
+				Vmid: int(plan.ID.ValueInt64()),
+			})
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error gettng  VM config",
+					"An unexpected error occurred when retreiving the VM config. "+
+						"Proxmox API Error: "+err.Error(),
+				)
+				return
+			}
+			plan.SMBIOS.UUID = smbiosUUID(config.Smbios1)
+			if plan.SMBIOS.UUID.IsNull() {
+				plan.SMBIOS.UUID = types.StringValue(uuid.NewString())
 			}
 		}
+		configReq.Smbios1 = proxmox.String(plan.SMBIOS.String())
 	}
 
-	if !plan.FWConfig.Equal(state.FWConfig) {
-		cfgString := ""
-		if plan.FWConfig.ValueString() != "" {
-			cfgString = "-fw_cfg " + plan.FWConfig.ValueString()
+	if !plan.Machine.Equal(state.Machine) {
+		if plan.Machine.ValueString() == "" {
+			toDel = append(toDel, "machine")
+		} else {
+			configReq.Machine = proxmox.String(plan.Machine.ValueString())
+		}
+	}
+
+	if !plan.OSType.Equal(state.OSType) {
+		if plan.OSType.ValueString() == "" {
+			toDel = append(toDel, "ostype")
+		} else {
+			configReq.Ostype = qemu.PtrOstype(qemu.Ostype(plan.OSType.ValueString()))
+		}
+	}
+
+	if !plan.VGA.Equal(state.VGA) {
+		if plan.VGA == nil {
+			toDel = append(toDel, "vga")
+		} else {
+			raw["vga"] = plan.VGA.String()
+		}
+	}
+
+	if args := argsString(plan.FWConfig, plan.Args); args != argsString(state.FWConfig, state.Args) {
+		if args == "" {
+			toDel = append(toDel, "args")
+		} else {
+			configReq.Args = proxmox.String(args)
+		}
+	}
+
+	if bootString(plan.BootOrder) != bootString(state.BootOrder) {
+		if len(plan.BootOrder) == 0 {
+			toDel = append(toDel, "boot")
+		} else {
+			configReq.Boot = proxmox.String(bootString(plan.BootOrder))
+		}
+	}
+
+	if !plan.OnBoot.Equal(state.OnBoot) {
+		if plan.OnBoot.IsNull() {
+			toDel = append(toDel, "onboot")
+		} else {
+			configReq.Onboot = proxmox.PVEBool(plan.OnBoot.ValueBool())
+		}
+	}
+
+	if !plan.Startup.Equal(state.Startup) {
+		if plan.Startup == nil {
+			toDel = append(toDel, "startup")
+		} else {
+			configReq.Startup = proxmox.String(plan.Startup.String())
+		}
+	}
+
+	if tagsString(plan.Tags) != tagsString(state.Tags) {
+		if len(plan.Tags) == 0 {
+			toDel = append(toDel, "tags")
+		} else {
+			configReq.Tags = proxmox.String(tagsString(plan.Tags))
+		}
+	}
+
+	if !plan.Desc.Equal(state.Desc) {
+		if plan.Desc.ValueString() == "" {
+			toDel = append(toDel, "description")
+		} else {
+			configReq.Description = proxmox.String(plan.Desc.ValueString())
 		}
-		configReq.Args = &cfgString
 	}
 
 	if len(plan.Networks) > 0 {
-		nets := make(qemu.Nets, 0)
+		nets := make(qemu.Nets, len(plan.Networks))
 		for i, net := range plan.Networks {
 			if len(state.Networks) <= i ||
-				state.Networks[i].Firewall != plan.Networks[i].Firewall ||
-				state.Networks[i].Bridge != plan.Networks[i].Bridge {
-				nets = append(nets, &qemu.Net{
+				!state.Networks[i].Equal(plan.Networks[i]) {
+				nets[i] = &qemu.Net{
 					Firewall: proxmox.PVEBool(net.Firewall.ValueBool()),
 					Bridge:   proxmox.String(net.Bridge.ValueString()),
 					Model:    qemu.NetModel_VIRTIO,
-				})
+				}
 			}
 		}
 		configReq.Nets = &nets
@@ -465,11 +1333,47 @@ func (r *resourceNodeVirtualMachine) Update(ctx context.Context, req resource.Up
 		toDel = append(toDel, fmt.Sprintf("scsi%d", i))
 	}
 
+	if len(plan.HostPCIs) > 0 {
+		hostpcis := make(qemu.Hostpcis, len(plan.HostPCIs))
+		for i, h := range plan.HostPCIs {
+			if len(state.HostPCIs) <= i ||
+				!state.HostPCIs[i].Equal(h) {
+				hostpcis[i] = proxmox.String(h.String())
+			}
+		}
+		configReq.Hostpcis = &hostpcis
+	}
+	for i := len(plan.HostPCIs); i < len(state.HostPCIs); i++ {
+		toDel = append(toDel, fmt.Sprintf("hostpci%d", i))
+	}
+
+	for i, u := range plan.USBs {
+		if len(state.USBs) <= i ||
+			!state.USBs[i].Equal(u) {
+			raw[fmt.Sprintf("usb%d", i)] = u.String()
+		}
+	}
+	for i := len(plan.USBs); i < len(state.USBs); i++ {
+		toDel = append(toDel, fmt.Sprintf("usb%d", i))
+	}
+
 	if len(toDel) > 0 {
 		configReq.Delete = proxmox.String(strings.Join(toDel, ","))
 	}
 
-	task, err := r.q.UpdateVmAsyncConfig(ctx, configReq)
+	err := retry.OnLock(ctx, "update VM", func() error {
+		task, err := r.q.UpdateVmAsyncConfigWithRaw(ctx, configReq, raw)
+		if err != nil {
+			return err
+		}
+		// Only a lock failure of the update task is retried, other task
+		// failures are picked up by the config read below.
+		err = r.t.WaitForExit(ctx, task, plan.Node.ValueString())
+		if apierr.Is(err, apierr.ErrLocked) {
+			return err
+		}
+		return nil
+	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error updating VM",
@@ -479,12 +1383,50 @@ func (r *resourceNodeVirtualMachine) Update(ctx context.Context, req resource.Up
 		return
 	}
 
-	_ = r.t.Wait(ctx, task, plan.Node.ValueString())
+	if plan.Pool.ValueString() != state.Pool.ValueString() {
+		vms := fmt.Sprintf("%d", plan.ID.ValueInt64())
+		if state.Pool.ValueString() != "" {
+			err = r.p.Update(ctx, pools.UpdateRequest{
+				Poolid: state.Pool.ValueString(),
+				Vms:    &vms,
+				Delete: proxmox.PVEBool(true),
+			})
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error removing VM from pool",
+					"An unexpected error occurred when removing the VM from its pool. "+
+						"Proxmox API Error: "+err.Error(),
+				)
+				return
+			}
+		}
+		if plan.Pool.ValueString() != "" {
+			err = r.p.Update(ctx, pools.UpdateRequest{
+				Poolid: plan.Pool.ValueString(),
+				Vms:    &vms,
+			})
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error adding VM to pool",
+					"An unexpected error occurred when adding the VM to a pool. "+
+						"Proxmox API Error: "+err.Error(),
+				)
+				return
+			}
+		}
+	}
+
 	if plan.Reboot.ValueBool() {
-		task, err = r.c.VmReboot(ctx, status.VmRebootRequest{
-			Node:    plan.Node.ValueString(),
-			Vmid:    int(plan.ID.ValueInt64()),
-			Timeout: proxmox.Int(300),
+		err = retry.OnLock(ctx, "reboot VM", func() error {
+			task, err := r.c.VmReboot(ctx, status.VmRebootRequest{
+				Node:    plan.Node.ValueString(),
+				Vmid:    int(plan.ID.ValueInt64()),
+				Timeout: proxmox.Int(300),
+			})
+			if err != nil {
+				return err
+			}
+			return r.t.WaitForExit(ctx, task, plan.Node.ValueString())
 		})
 		if err != nil {
 			resp.Diagnostics.AddError(
@@ -494,12 +1436,15 @@ func (r *resourceNodeVirtualMachine) Update(ctx context.Context, req resource.Up
 			)
 			return
 		}
-		diags = r.t.Wait(ctx, task, plan.Node.ValueString())
-		resp.Diagnostics.Append(diags...)
+	}
+
+	if plan.Template.ValueBool() && !state.Template.ValueBool() {
+		resp.Diagnostics.Append(r.convertToTemplate(ctx, &plan)...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
 	}
+
 	config, err := r.q.VmConfig(ctx, qemu.VmConfigRequest{
 		Node: plan.Node.ValueString(),
 		Vmid: int(plan.ID.ValueInt64()),
@@ -512,6 +1457,9 @@ func (r *resourceNodeVirtualMachine) Update(ctx context.Context, req resource.Up
 		)
 		return
 	}
+	if plan.SMBIOS != nil {
+		plan.SMBIOS.UUID = smbiosUUID(config.Smbios1)
+	}
 	for i, d := range plan.Scsis {
 		if config.Scsis == nil || len(*config.Scsis) <= i {
 			resp.Diagnostics.AddError(
@@ -533,6 +1481,14 @@ func (r *resourceNodeVirtualMachine) Update(ctx context.Context, req resource.Up
 		d.VolumeID = types.StringValue((*config.Ides)[i].File)
 	}
 
+	// Older states will not have the guest addresses to carry forward
+	if plan.NetworkInterfaces.IsUnknown() {
+		resp.Diagnostics.Append(r.readGuestNetwork(ctx, &plan, true)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -556,6 +1512,347 @@ func (d *Disk) Equal(other *Disk) bool {
 		d.Backup.Equal(other.Backup)
 }
 
+func (n *Network) Equal(other *Network) bool {
+	if other == nil && n == nil {
+		return true
+	}
+	if other == nil || n == nil {
+		return false
+	}
+	return n.Bridge.Equal(other.Bridge) &&
+		n.Firewall.Equal(other.Firewall)
+}
+
+func (n *Network) buildNetwork(net *qemu.Net) {
+	if net.Bridge != nil {
+		n.Bridge = types.StringValue(*net.Bridge)
+	} else {
+		n.Bridge = types.StringNull()
+	}
+	n.Firewall = optionalBool(n.Firewall, (*bool)(net.Firewall), false)
+}
+
+func (s *Serial) Equal(other *Serial) bool {
+	if other == nil && s == nil {
+		return true
+	}
+	if other == nil || s == nil {
+		return false
+	}
+	return s.Device.Equal(other.Device)
+}
+
+func (s *SMBIOS) Equal(other *SMBIOS) bool {
+	if other == nil && s == nil {
+		return true
+	}
+	if other == nil || s == nil {
+		return false
+	}
+	return s.UUID.Equal(other.UUID) &&
+		s.Manufacturer.Equal(other.Manufacturer) &&
+		s.Product.Equal(other.Product) &&
+		s.Serial.Equal(other.Serial) &&
+		s.SKU.Equal(other.SKU) &&
+		s.Family.Equal(other.Family)
+}
+
+// String encodes the smbios1 option, the free form values are always base64
+// encoded so they may contain commas and other special characters.
+func (s *SMBIOS) String() string {
+	parts := []string{}
+	if s.UUID.ValueString() != "" {
+		parts = append(parts, "uuid="+s.UUID.ValueString())
+	}
+	for _, field := range []struct {
+		key   string
+		value types.String
+	}{
+		{"manufacturer", s.Manufacturer},
+		{"product", s.Product},
+		{"serial", s.Serial},
+		{"sku", s.SKU},
+		{"family", s.Family},
+	} {
+		if field.value.ValueString() != "" {
+			parts = append(parts, field.key+"="+base64.StdEncoding.EncodeToString([]byte(field.value.ValueString())))
+		}
+	}
+	parts = append(parts, "base64=1")
+	return strings.Join(parts, ",")
+}
+
+func (s *SMBIOS) buildSMBIOS(in *string) {
+	values := map[string]string{}
+	if in != nil {
+		values = parsePropertyString(*in, "uuid")
+	}
+	decode := func(key string) types.String {
+		v, ok := values[key]
+		if !ok {
+			return types.StringNull()
+		}
+		if values["base64"] == "1" {
+			if decoded, err := base64.StdEncoding.DecodeString(v); err == nil {
+				v = string(decoded)
+			}
+		}
+		return types.StringValue(v)
+	}
+	s.UUID = propertyString(values, "uuid")
+	s.Manufacturer = decode("manufacturer")
+	s.Product = decode("product")
+	s.Serial = decode("serial")
+	s.SKU = decode("sku")
+	s.Family = decode("family")
+}
+
+func smbiosUUID(in *string) types.String {
+	if in == nil {
+		return types.StringNull()
+	}
+	return propertyString(parsePropertyString(*in, "uuid"), "uuid")
+}
+
+func (v *VGA) Equal(other *VGA) bool {
+	if other == nil && v == nil {
+		return true
+	}
+	if other == nil || v == nil {
+		return false
+	}
+	return v.Type.Equal(other.Type) &&
+		v.Memory.Equal(other.Memory) &&
+		v.Clipboard.Equal(other.Clipboard)
+}
+
+func (v *VGA) String() string {
+	parts := []string{}
+	if v.Type.ValueString() != "" {
+		parts = append(parts, "type="+v.Type.ValueString())
+	}
+	if !v.Memory.IsNull() {
+		parts = append(parts, fmt.Sprintf("memory=%d", v.Memory.ValueInt64()))
+	}
+	if v.Clipboard.ValueString() != "" {
+		parts = append(parts, "clipboard="+v.Clipboard.ValueString())
+	}
+	return strings.Join(parts, ",")
+}
+
+func (v *VGA) buildVGA(in string) {
+	values := parsePropertyString(in, "type")
+	v.Type = propertyString(values, "type")
+	v.Memory = propertyInt64(values, "memory")
+	v.Clipboard = propertyString(values, "clipboard")
+}
+
+func (a *Agent) Equal(other *Agent) bool {
+	if other == nil && a == nil {
+		return true
+	}
+	if other == nil || a == nil {
+		return false
+	}
+	return a.Enabled.Equal(other.Enabled) &&
+		a.Type.Equal(other.Type) &&
+		a.FstrimClonedDisks.Equal(other.FstrimClonedDisks) &&
+		a.FreezeFSOnBackup.Equal(other.FreezeFSOnBackup)
+}
+
+func (a *Agent) String() string {
+	parts := []string{"enabled=" + pveBool(a.Enabled.ValueBool())}
+	if a.Type.ValueString() != "" {
+		parts = append(parts, "type="+a.Type.ValueString())
+	}
+	if !a.FstrimClonedDisks.IsNull() {
+		parts = append(parts, "fstrim_cloned_disks="+pveBool(a.FstrimClonedDisks.ValueBool()))
+	}
+	if !a.FreezeFSOnBackup.IsNull() {
+		parts = append(parts, "freeze-fs-on-backup="+pveBool(a.FreezeFSOnBackup.ValueBool()))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (a *Agent) buildAgent(in string) {
+	values := parsePropertyString(in, "enabled")
+	a.Enabled = types.BoolValue(values["enabled"] == "1")
+	a.Type = propertyString(values, "type")
+	a.FstrimClonedDisks = propertyBool(a.FstrimClonedDisks, values, "fstrim_cloned_disks", false)
+	a.FreezeFSOnBackup = propertyBool(a.FreezeFSOnBackup, values, "freeze-fs-on-backup", true)
+}
+
+func (s *Startup) Equal(other *Startup) bool {
+	if other == nil && s == nil {
+		return true
+	}
+	if other == nil || s == nil {
+		return false
+	}
+	return s.Order.Equal(other.Order) &&
+		s.Up.Equal(other.Up) &&
+		s.Down.Equal(other.Down)
+}
+
+func (s *Startup) String() string {
+	parts := []string{}
+	if !s.Order.IsNull() {
+		parts = append(parts, fmt.Sprintf("order=%d", s.Order.ValueInt64()))
+	}
+	if !s.Up.IsNull() {
+		parts = append(parts, fmt.Sprintf("up=%d", s.Up.ValueInt64()))
+	}
+	if !s.Down.IsNull() {
+		parts = append(parts, fmt.Sprintf("down=%d", s.Down.ValueInt64()))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s *Startup) buildStartup(in string) {
+	values := parsePropertyString(in, "order")
+	s.Order = propertyInt64(values, "order")
+	s.Up = propertyInt64(values, "up")
+	s.Down = propertyInt64(values, "down")
+}
+
+// tagsString joins tags in the sorted form proxmox stores them in
+func tagsString(tags []types.String) string {
+	t := make([]string, len(tags))
+	for i, tag := range tags {
+		t[i] = tag.ValueString()
+	}
+	sort.Strings(t)
+	return strings.Join(t, ";")
+}
+
+func parseTags(tags *string) []types.String {
+	if tags == nil || *tags == "" {
+		return nil
+	}
+	t := []types.String{}
+	for _, tag := range strings.FieldsFunc(*tags, func(r rune) bool {
+		return r == ';' || r == ',' || r == ' '
+	}) {
+		t = append(t, types.StringValue(tag))
+	}
+	return t
+}
+
+func bootString(order []types.String) string {
+	if len(order) == 0 {
+		return ""
+	}
+	devices := make([]string, len(order))
+	for i, d := range order {
+		devices[i] = d.ValueString()
+	}
+	return "order=" + strings.Join(devices, ";")
+}
+
+func parseBootOrder(boot *string) []types.String {
+	order := make([]types.String, 0)
+	if boot == nil {
+		return order
+	}
+	values := parsePropertyString(*boot, "legacy")
+	if devices, ok := values["order"]; ok && devices != "" {
+		for _, d := range strings.Split(devices, ";") {
+			order = append(order, types.StringValue(d))
+		}
+	}
+	return order
+}
+
+func (h *HostPCI) Equal(other *HostPCI) bool {
+	if other == nil && h == nil {
+		return true
+	}
+	if other == nil || h == nil {
+		return false
+	}
+	return h.ID.Equal(other.ID) &&
+		h.Mapping.Equal(other.Mapping) &&
+		h.PCIE.Equal(other.PCIE) &&
+		h.ROMBar.Equal(other.ROMBar) &&
+		h.XVGA.Equal(other.XVGA) &&
+		h.MDev.Equal(other.MDev)
+}
+
+func (h *HostPCI) String() string {
+	parts := []string{}
+	if h.Mapping.ValueString() != "" {
+		parts = append(parts, "mapping="+h.Mapping.ValueString())
+	} else {
+		parts = append(parts, "host="+h.ID.ValueString())
+	}
+	if !h.PCIE.IsNull() {
+		parts = append(parts, "pcie="+pveBool(h.PCIE.ValueBool()))
+	}
+	if !h.ROMBar.IsNull() {
+		parts = append(parts, "rombar="+pveBool(h.ROMBar.ValueBool()))
+	}
+	if !h.XVGA.IsNull() {
+		parts = append(parts, "x-vga="+pveBool(h.XVGA.ValueBool()))
+	}
+	if h.MDev.ValueString() != "" {
+		parts = append(parts, "mdev="+h.MDev.ValueString())
+	}
+	return strings.Join(parts, ",")
+}
+
+func (h *HostPCI) buildHostPCI(in string) {
+	values := parsePropertyString(in, "host")
+	h.ID = propertyString(values, "host")
+	h.Mapping = propertyString(values, "mapping")
+	h.MDev = propertyString(values, "mdev")
+	h.PCIE = propertyBool(h.PCIE, values, "pcie", false)
+	h.ROMBar = propertyBool(h.ROMBar, values, "rombar", true)
+	h.XVGA = propertyBool(h.XVGA, values, "x-vga", false)
+}
+
+func (u *USB) Equal(other *USB) bool {
+	if other == nil && u == nil {
+		return true
+	}
+	if other == nil || u == nil {
+		return false
+	}
+	return u.ID.Equal(other.ID) &&
+		u.Port.Equal(other.Port) &&
+		u.Mapping.Equal(other.Mapping) &&
+		u.USB3.Equal(other.USB3)
+}
+
+func (u *USB) String() string {
+	parts := []string{}
+	if u.Mapping.ValueString() != "" {
+		parts = append(parts, "mapping="+u.Mapping.ValueString())
+	} else if u.Port.ValueString() != "" {
+		parts = append(parts, "host="+u.Port.ValueString())
+	} else {
+		parts = append(parts, "host="+u.ID.ValueString())
+	}
+	if !u.USB3.IsNull() {
+		parts = append(parts, "usb3="+pveBool(u.USB3.ValueBool()))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (u *USB) buildUSB(in string) {
+	values := parsePropertyString(in, "host")
+	u.ID = types.StringNull()
+	u.Port = types.StringNull()
+	if host, ok := values["host"]; ok {
+		if usbPortRegex.MatchString(host) {
+			u.Port = types.StringValue(host)
+		} else {
+			u.ID = types.StringValue(host)
+		}
+	}
+	u.Mapping = propertyString(values, "mapping")
+	u.USB3 = propertyBool(u.USB3, values, "usb3", false)
+}
+
 func (r *resourceNodeVirtualMachine) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var state resourceNodeVirtualMachineModel
 	diags := req.State.Get(ctx, &state)
@@ -564,12 +1861,13 @@ func (r *resourceNodeVirtualMachine) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	config, err := r.q.VmConfig(ctx, qemu.VmConfigRequest{
+	config, raw, err := r.q.VmConfigWithRaw(ctx, qemu.VmConfigRequest{
 		Node: state.Node.ValueString(),
 		Vmid: int(state.ID.ValueInt64()),
 	})
 	if err != nil {
-		if err.Error() == fmt.Sprintf("non 200: 500 Configuration file 'nodes/%s/qemu-server/%d.conf' does not exist", state.Node.ValueString(), state.ID.ValueInt64()) {
+		if apierr.Is(err, apierr.ErrGuestNotFound) {
+			resp.State.RemoveResource(ctx)
 			return
 		}
 		resp.Diagnostics.AddError(
@@ -590,16 +1888,117 @@ func (r *resourceNodeVirtualMachine) Read(ctx context.Context, req resource.Read
 	state.Memory = types.Int64Value(int64(*config.Memory))
 	state.CPUs = types.Int64Value(int64(*config.Cores))
 
-	if config.Args != nil {
-		if strings.HasPrefix(*config.Args, "-fw_cfg ") {
-			state.FWConfig = types.StringValue(strings.TrimPrefix(*config.Args, "-fw_cfg "))
+	if config.Name != nil {
+		state.Name = types.StringValue(*config.Name)
+	} else {
+		state.Name = types.StringNull()
+	}
+
+	state.FWConfig, state.Args = parseArgs(config.Args, state.FWConfig, state.Args)
+
+	newSerials := make([]*Serial, 0)
+	if config.Serials != nil {
+		for _, serial := range *config.Serials {
+			s := &Serial{Device: types.StringNull()}
+			if serial != nil {
+				s.Device = types.StringValue(*serial)
+			}
+			newSerials = append(newSerials, s)
+		}
+	}
+	state.Serials = newSerials
+
+	if vga, ok := rawString(raw, "vga"); ok {
+		if state.VGA == nil {
+			state.VGA = &VGA{}
+		}
+		state.VGA.buildVGA(vga)
+	} else {
+		state.VGA = nil
+	}
+
+	// Proxmox fills in a default boot order, only track it once managed
+	if state.BootOrder != nil {
+		state.BootOrder = parseBootOrder(config.Boot)
+	}
+
+	state.OnBoot = optionalBool(state.OnBoot, (*bool)(config.Onboot), false)
+	state.Template = optionalBool(state.Template, (*bool)(config.Template), false)
+
+	// Proxmox generates smbios1 with a UUID for every VM, only track it once
+	// managed
+	if state.SMBIOS != nil {
+		state.SMBIOS.buildSMBIOS(config.Smbios1)
+	}
+
+	if config.Machine != nil {
+		state.Machine = types.StringValue(*config.Machine)
+	} else {
+		state.Machine = types.StringNull()
+	}
+
+	if config.Ostype != nil {
+		state.OSType = types.StringValue(string(*config.Ostype))
+	} else {
+		state.OSType = types.StringNull()
+	}
+
+	if agentConfig, ok := rawString(raw, "agent"); ok {
+		if state.Agent == nil {
+			state.Agent = &Agent{}
+		}
+		state.Agent.buildAgent(agentConfig)
+	} else {
+		state.Agent = nil
+	}
+
+	if config.Startup != nil {
+		if state.Startup == nil {
+			state.Startup = &Startup{}
+		}
+		state.Startup.buildStartup(*config.Startup)
+	} else {
+		state.Startup = nil
+	}
+
+	state.Tags = parseTags(config.Tags)
+
+	if config.Description != nil {
+		state.Desc = types.StringValue(*config.Description)
+	} else {
+		state.Desc = types.StringNull()
+	}
+
+	vms, err := r.cl.Resources(ctx, cluster.ResourcesRequest{
+		Type: cluster.PtrType(cluster.Type_VM),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error gettng VM pool",
+			"An unexpected error occurred when retreiving the VM pool. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+	state.Pool = types.StringNull()
+	for _, vm := range vms {
+		if vm.Vmid != nil && int64(*vm.Vmid) == state.ID.ValueInt64() &&
+			vm.Pool != nil && *vm.Pool != "" {
+			state.Pool = types.StringValue(*vm.Pool)
 		}
 	}
 
+	resp.Diagnostics.Append(r.readGuestNetwork(ctx, &state, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	if config.Ides != nil {
 		newState := make([]*Disk, len(*config.Ides))
 		for i, ide := range *config.Ides {
 			if ide == nil {
+				// Removed out of band, leave a blank disk so the gap is planned
+				newState[i] = &Disk{}
 				continue
 			}
 			if len(state.Ides) <= i || state.Ides[i] == nil || state.Ides[i].VolumeID.ValueString() != ide.File {
@@ -622,6 +2021,7 @@ func (r *resourceNodeVirtualMachine) Read(ctx context.Context, req resource.Read
 		newState := make([]*Disk, len(*config.Scsis))
 		for i, scsi := range *config.Scsis {
 			if scsi == nil {
+				newState[i] = &Disk{}
 				continue
 			}
 			if len(state.Scsis) <= i || state.Scsis[i] == nil || state.Scsis[i].VolumeID.ValueString() != scsi.File {
@@ -640,28 +2040,60 @@ func (r *resourceNodeVirtualMachine) Read(ctx context.Context, req resource.Read
 		state.Scsis = make([]*Disk, 0)
 	}
 
-	if state.Networks == nil {
-		state.Networks = make([]*Network, 0)
-	}
 	if config.Nets != nil {
+		newState := make([]*Network, len(*config.Nets))
 		for i, net := range *config.Nets {
-			for i >= len(state.Networks) {
-				state.Networks = append(state.Networks, nil)
+			if len(state.Networks) <= i || state.Networks[i] == nil {
+				newState[i] = &Network{}
+			} else {
+				newState[i] = state.Networks[i]
 			}
-
-			if state.Networks[i] == nil {
-				state.Networks[i] = &Network{}
+			if net == nil {
+				newState[i] = &Network{}
+				continue
 			}
-			n := state.Networks[i]
-			if net.Firewall != nil {
-				n.Firewall = types.BoolValue(bool(*net.Firewall))
+			newState[i].buildNetwork(net)
+		}
+		state.Networks = newState
+	} else {
+		state.Networks = make([]*Network, 0)
+	}
+
+	if config.Hostpcis != nil {
+		newState := make([]*HostPCI, len(*config.Hostpcis))
+		for i, hostpci := range *config.Hostpcis {
+			if hostpci == nil {
+				newState[i] = &HostPCI{}
+				continue
 			}
-			if net.Bridge != nil {
-				n.Bridge = types.StringValue(*net.Bridge)
+			if len(state.HostPCIs) <= i || state.HostPCIs[i] == nil {
+				newState[i] = &HostPCI{}
+			} else {
+				newState[i] = state.HostPCIs[i]
 			}
+			newState[i].buildHostPCI(*hostpci)
 		}
+		state.HostPCIs = newState
+	} else {
+		state.HostPCIs = make([]*HostPCI, 0)
 	}
 
+	newUSBs := make([]*USB, 0)
+	for i := 0; i < maxUSBDevices; i++ {
+		usb, ok := rawString(raw, fmt.Sprintf("usb%d", i))
+		if !ok {
+			continue
+		}
+		for len(newUSBs) <= i {
+			newUSBs = append(newUSBs, &USB{})
+		}
+		if len(state.USBs) > i && state.USBs[i] != nil {
+			newUSBs[i] = state.USBs[i]
+		}
+		newUSBs[i].buildUSB(usb)
+	}
+	state.USBs = newUSBs
+
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -669,24 +2101,185 @@ func (r *resourceNodeVirtualMachine) Read(ctx context.Context, req resource.Read
 	}
 }
 
-func (d *Disk) buildDisk(i int, file string, snapshot *bool, backup *bool) diag.Diagnostics {
+// convertToTemplate turns the VM into a template, converting its disks into
+// base images.
+func (r *resourceNodeVirtualMachine) convertToTemplate(ctx context.Context, plan *resourceNodeVirtualMachineModel) diag.Diagnostics {
 	diags := diag.Diagnostics{}
-	storageID := strings.Split(file, ":")[0]
-	if !strings.HasPrefix(file, "/dev") {
-		d.Storage = types.StringValue(storageID)
+	err := retry.OnLock(ctx, "convert VM to template", func() error {
+		task, err := r.q.Template(ctx, qemu.TemplateRequest{
+			Node: plan.Node.ValueString(),
+			Vmid: int(plan.ID.ValueInt64()),
+		})
+		if err != nil {
+			return err
+		}
+		return r.t.WaitForExit(ctx, task, plan.Node.ValueString())
+	})
+	if err != nil {
+		diags.AddError(
+			"Error converting VM to template",
+			"An unexpected error occurred when converting the VM to a template. "+
+				"Proxmox API Error: "+err.Error(),
+		)
 	}
-	d.VolumeID = types.StringValue(file)
-	if snapshot != nil {
-		if !d.Readonly.IsNull() || *snapshot {
-			d.Readonly = types.BoolValue(*snapshot)
+	return diags
+}
+
+// readGuestNetwork populates the guest addresses reported by the qemu guest
+// agent. When wait is false the agent is queried once, if it does not answer
+// the addresses already in state are kept.
+func (r *resourceNodeVirtualMachine) readGuestNetwork(ctx context.Context, state *resourceNodeVirtualMachineModel, wait bool) diag.Diagnostics {
+	interfaces, answered, diags := r.guestInterfaces(ctx, state, wait)
+	if diags.HasError() {
+		return diags
+	}
+	if !answered && !wait && knownGuestNetwork(state) {
+		return diags
+	}
+
+	ipv4Addresses := []types.String{}
+	ipv6Addresses := []types.String{}
+	networkInterfaces := []*NetworkInterface{}
+	for _, iface := range interfaces {
+		n := &NetworkInterface{
+			Name:          types.StringValue(iface.Name),
+			MACAddress:    types.StringValue(iface.HardwareAddress),
+			IPv4Addresses: []types.String{},
+			IPv6Addresses: []types.String{},
+		}
+		for _, addr := range iface.IPAddresses {
+			ip := net.ParseIP(addr.IPAddress)
+			if ip == nil {
+				continue
+			}
+			if addr.IPAddressType == "ipv4" {
+				n.IPv4Addresses = append(n.IPv4Addresses, types.StringValue(addr.IPAddress))
+				if !ip.IsLoopback() {
+					ipv4Addresses = append(ipv4Addresses, types.StringValue(addr.IPAddress))
+				}
+			} else {
+				n.IPv6Addresses = append(n.IPv6Addresses, types.StringValue(addr.IPAddress))
+				if !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+					ipv6Addresses = append(ipv6Addresses, types.StringValue(addr.IPAddress))
+				}
+			}
 		}
+		networkInterfaces = append(networkInterfaces, n)
 	}
 
-	if backup != nil {
-		if !d.Backup.IsNull() || *backup {
-			d.Backup = types.BoolValue(*backup)
+	var d diag.Diagnostics
+	state.IPv4Addresses, d = types.ListValueFrom(ctx, types.StringType, ipv4Addresses)
+	diags.Append(d...)
+	state.IPv6Addresses, d = types.ListValueFrom(ctx, types.StringType, ipv6Addresses)
+	diags.Append(d...)
+	state.NetworkInterfaces, d = types.ListValueFrom(ctx, networkInterfaceType, networkInterfaces)
+	diags.Append(d...)
+	return diags
+}
+
+// guestInterfaces queries the guest agent of a running VM. When wait is set
+// it retries for up to guest_agent_timeout until the agent reports an
+// address. answered is false when the agent could not be reached.
+func (r *resourceNodeVirtualMachine) guestInterfaces(ctx context.Context, state *resourceNodeVirtualMachineModel, wait bool) (interfaces []agentInterface, answered bool, diags diag.Diagnostics) {
+	interfaces = []agentInterface{}
+	if state.Agent == nil || !state.Agent.Enabled.ValueBool() {
+		return interfaces, true, diags
+	}
+
+	var vmStatus status.VmStatusCurrentResponse
+	err := retry.OnLock(ctx, "get VM status", func() error {
+		var err error
+		vmStatus, err = r.c.VmStatusCurrent(ctx, status.VmStatusCurrentRequest{
+			Node: state.Node.ValueString(),
+			Vmid: int(state.ID.ValueInt64()),
+		})
+		return err
+	})
+	if err != nil {
+		diags.AddError(
+			"Error gettng VM status",
+			"An unexpected error occurred when retreiving the VM status. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return interfaces, false, diags
+	}
+	if vmStatus.Status != status.Status_RUNNING {
+		return interfaces, true, diags
+	}
+
+	timeout := state.GuestAgentTimeout.ValueInt64()
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		resp, err := r.a.NetworkGetInterfaces(ctx, agent.NetworkGetInterfacesRequest{
+			Node: state.Node.ValueString(),
+			Vmid: int(state.ID.ValueInt64()),
+		})
+		if err == nil {
+			interfaces, err = parseAgentInterfaces(resp)
+			if err != nil {
+				diags.AddError(
+					"Error parsing guest agent response",
+					"An unexpected error occurred when parsing the guest agent interfaces. "+
+						"Error: "+err.Error(),
+				)
+				return interfaces, false, diags
+			}
+			answered = true
+			if !wait || hasGuestAddress(interfaces) {
+				return interfaces, answered, diags
+			}
+		} else if !wait {
+			return interfaces, false, diags
+		}
+		if time.Now().After(deadline) {
+			if timeout > 0 {
+				diags.AddWarning(
+					"Guest agent did not report an address",
+					fmt.Sprintf("The guest agent did not report a network address within %d seconds.", timeout),
+				)
+			}
+			return interfaces, answered, diags
+		}
+		select {
+		case <-ctx.Done():
+			return interfaces, answered, diags
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// knownGuestNetwork reports whether state already holds guest addresses that
+// can be kept when the agent does not answer.
+func knownGuestNetwork(state *resourceNodeVirtualMachineModel) bool {
+	for _, l := range []types.List{state.IPv4Addresses, state.IPv6Addresses, state.NetworkInterfaces} {
+		if l.IsNull() || l.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+func hasGuestAddress(interfaces []agentInterface) bool {
+	for _, iface := range interfaces {
+		for _, addr := range iface.IPAddresses {
+			ip := net.ParseIP(addr.IPAddress)
+			if ip != nil && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+				return true
+			}
 		}
 	}
+	return false
+}
+
+func (d *Disk) buildDisk(i int, file string, snapshot *bool, backup *bool) diag.Diagnostics {
+	diags := diag.Diagnostics{}
+	storageID := strings.Split(file, ":")[0]
+	if !strings.HasPrefix(file, "/dev") {
+		d.Storage = types.StringValue(storageID)
+	}
+	d.VolumeID = types.StringValue(file)
+	d.Readonly = optionalBool(d.Readonly, snapshot, false)
+	d.Backup = optionalBool(d.Backup, backup, true)
 	return diags
 }
 
@@ -705,5 +2298,6 @@ func proxmoxDisk(d *Disk, qd wrappedDisk) {
 	}
 
 	qd.SetSnapshot(d.Readonly.ValueBool())
-	qd.SetBackup(d.Backup.ValueBool())
+	// proxmox backs up disks unless told otherwise
+	qd.SetBackup(d.Backup.IsNull() || d.Backup.ValueBool())
 }
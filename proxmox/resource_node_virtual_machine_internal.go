@@ -1,8 +1,14 @@
 package proxmox
 
 import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
 	"github.com/FreekingDean/proxmox-api-go/proxmox"
 	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/qemu"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type wrappedScsi qemu.Scsi
@@ -119,3 +125,206 @@ type wrappedDisk interface {
 	SetSnapshot(bool)
 	SetBackup(bool)
 }
+
+// rawConfig holds qemu config keys which the generated api types are unable
+// to fully represent (i.e. usb mappings)
+type rawConfig map[string]string
+
+func (c rawConfig) EncodeValues(_ string, v *url.Values) error {
+	for key, value := range c {
+		v.Set(key, value)
+	}
+	return nil
+}
+
+type rawCreateRequest struct {
+	qemu.CreateRequest
+	Raw rawConfig `url:"raw,omitempty"`
+}
+
+type rawUpdateVmAsyncConfigRequest struct {
+	qemu.UpdateVmAsyncConfigRequest
+	Raw rawConfig `url:"raw,omitempty"`
+}
+
+type qemuClient struct {
+	*qemu.Client
+	p qemu.HTTPClient
+}
+
+func newQemuClient(p qemu.HTTPClient) *qemuClient {
+	return &qemuClient{
+		Client: qemu.New(p),
+		p:      p,
+	}
+}
+
+func (c *qemuClient) CreateWithRaw(ctx context.Context, req qemu.CreateRequest, raw rawConfig) (string, error) {
+	var resp string
+
+	err := c.p.Do(ctx, "/nodes/{node}/qemu", "POST", &resp, rawCreateRequest{req, raw})
+	return resp, err
+}
+
+func (c *qemuClient) UpdateVmAsyncConfigWithRaw(ctx context.Context, req qemu.UpdateVmAsyncConfigRequest, raw rawConfig) (string, error) {
+	var resp string
+
+	err := c.p.Do(ctx, "/nodes/{node}/qemu/{vmid}/config", "POST", &resp, rawUpdateVmAsyncConfigRequest{req, raw})
+	return resp, err
+}
+
+// VmConfigWithRaw returns the parsed config along with the raw key/values for
+// any options the generated types drop
+func (c *qemuClient) VmConfigWithRaw(ctx context.Context, req qemu.VmConfigRequest) (qemu.VmConfigResponse, map[string]interface{}, error) {
+	var data json.RawMessage
+	config := qemu.VmConfigResponse{}
+	raw := map[string]interface{}{}
+
+	err := c.p.Do(ctx, "/nodes/{node}/qemu/{vmid}/config", "GET", &data, req)
+	if err != nil {
+		return config, raw, err
+	}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return config, raw, err
+	}
+	err = json.Unmarshal(data, &raw)
+	return config, raw, err
+}
+
+// argsString builds the qemu args option from the fw_config entry followed
+// by the additional arguments, quoting each argument as needed.
+func argsString(fwConfig types.String, args []types.String) string {
+	tokens := []string{}
+	if fwConfig.ValueString() != "" {
+		tokens = append(tokens, "-fw_cfg", fwConfig.ValueString())
+	}
+	for _, arg := range args {
+		tokens = append(tokens, arg.ValueString())
+	}
+	quoted := make([]string, len(tokens))
+	for i, token := range tokens {
+		quoted[i] = quoteArg(token)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// parseArgs splits the qemu args option back into the fw_config entry and the
+// additional arguments. When fw_config is managed the first -fw_cfg entry is
+// pulled out of the arguments, any further entries stay in args. An empty
+// args list in the prior state is kept empty instead of becoming null.
+func parseArgs(in *string, fwConfig types.String, args []types.String) (types.String, []types.String) {
+	newFWConfig := types.StringNull()
+	var newArgs []types.String
+	if args != nil {
+		newArgs = []types.String{}
+	}
+	if in == nil {
+		return newFWConfig, newArgs
+	}
+
+	tokens := splitArgs(*in)
+	for i := 0; i < len(tokens); i++ {
+		if !fwConfig.IsNull() && newFWConfig.IsNull() && tokens[i] == "-fw_cfg" && i+1 < len(tokens) {
+			newFWConfig = types.StringValue(tokens[i+1])
+			i++
+			continue
+		}
+		newArgs = append(newArgs, types.StringValue(tokens[i]))
+	}
+	return newFWConfig, newArgs
+}
+
+// quoteArg quotes an argument for the shell like splitting proxmox applies to
+// the args option.
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// splitArgs splits the args option into arguments honoring single quotes,
+// double quotes and backslash escapes.
+func splitArgs(in string) []string {
+	args := []string{}
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, c := range in {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+func rawString(raw map[string]interface{}, key string) (string, bool) {
+	v, ok := raw[key]
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+type agentIPAddress struct {
+	IPAddress     string `json:"ip-address"`
+	IPAddressType string `json:"ip-address-type"`
+	Prefix        int    `json:"prefix"`
+}
+
+type agentInterface struct {
+	Name            string           `json:"name"`
+	HardwareAddress string           `json:"hardware-address"`
+	IPAddresses     []agentIPAddress `json:"ip-addresses"`
+}
+
+// parseAgentInterfaces converts the loosely typed network-get-interfaces
+// agent response into its interfaces
+func parseAgentInterfaces(resp map[string]interface{}) ([]agentInterface, error) {
+	interfaces := []agentInterface{}
+	result, ok := resp["result"]
+	if !ok {
+		return interfaces, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return interfaces, err
+	}
+	err = json.Unmarshal(data, &interfaces)
+	return interfaces, err
+}
@@ -0,0 +1,260 @@
+package proxmox
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func stringValues(in ...string) []types.String {
+	if in == nil {
+		return nil
+	}
+	out := make([]types.String, len(in))
+	for i, s := range in {
+		out[i] = types.StringValue(s)
+	}
+	return out
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{
+			name: "empty",
+			in:   "",
+			want: []string{},
+		},
+		{
+			name: "whitespace",
+			in:   " \t\n",
+			want: []string{},
+		},
+		{
+			name: "plain",
+			in:   "-cpu host,+aes",
+			want: []string{"-cpu", "host,+aes"},
+		},
+		{
+			name: "repeated whitespace",
+			in:   "  -cpu\t\thost  ",
+			want: []string{"-cpu", "host"},
+		},
+		{
+			name: "single quotes",
+			in:   `-fw_cfg 'name=opt/a,string=hello world'`,
+			want: []string{"-fw_cfg", "name=opt/a,string=hello world"},
+		},
+		{
+			name: "double quotes",
+			in:   `-smbios "type=0,vendor=My Vendor"`,
+			want: []string{"-smbios", "type=0,vendor=My Vendor"},
+		},
+		{
+			name: "escaped space",
+			in:   `-name my\ vm`,
+			want: []string{"-name", "my vm"},
+		},
+		{
+			name: "escaped quote in double quotes",
+			in:   `"say \"hi\""`,
+			want: []string{`say "hi"`},
+		},
+		{
+			name: "backslash in single quotes",
+			in:   `'a\b'`,
+			want: []string{`a\b`},
+		},
+		{
+			name: "single quote in single quotes",
+			in:   `'it'\''s'`,
+			want: []string{"it's"},
+		},
+		{
+			name: "empty quoted argument",
+			in:   `-a '' -b ""`,
+			want: []string{"-a", "", "-b", ""},
+		},
+		{
+			name: "adjacent quotes join",
+			in:   `a'b c'"d"`,
+			want: []string{"ab cd"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitArgs(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "-cpu", want: "-cpu"},
+		{in: "host,+aes", want: "host,+aes"},
+		{in: "name=opt/a,file=/tmp/x", want: "name=opt/a,file=/tmp/x"},
+		{in: "", want: "''"},
+		{in: "hello world", want: "'hello world'"},
+		{in: "it's", want: `'it'\''s'`},
+		{in: `say "hi"`, want: `'say "hi"'`},
+		{in: `a\b`, want: `'a\b'`},
+		{in: "$HOME", want: "'$HOME'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := quoteArg(tt.in)
+			if got != tt.want {
+				t.Errorf("quoteArg(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			// Every quoted argument must split back into itself
+			if split := splitArgs(got); len(split) != 1 || split[0] != tt.in {
+				t.Errorf("splitArgs(quoteArg(%q)) = %q", tt.in, split)
+			}
+		})
+	}
+}
+
+func TestArgsString(t *testing.T) {
+	tests := []struct {
+		name     string
+		fwConfig types.String
+		args     []types.String
+		want     string
+	}{
+		{
+			name:     "nothing",
+			fwConfig: types.StringNull(),
+			want:     "",
+		},
+		{
+			name:     "empty args",
+			fwConfig: types.StringNull(),
+			args:     stringValues(),
+			want:     "",
+		},
+		{
+			name:     "fw_config first",
+			fwConfig: types.StringValue("name=opt/a,string=1"),
+			args:     stringValues("-fw_cfg", "name=opt/b,string=two words", "-cpu", "host"),
+			want:     "-fw_cfg name=opt/a,string=1 -fw_cfg 'name=opt/b,string=two words' -cpu host",
+		},
+		{
+			name:     "args only",
+			fwConfig: types.StringNull(),
+			args:     stringValues("-smbios", "type=0,vendor=it's"),
+			want:     `-smbios 'type=0,vendor=it'\''s'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := argsString(tt.fwConfig, tt.args)
+			if got != tt.want {
+				t.Errorf("argsString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name         string
+		in           *string
+		fwConfig     types.String
+		args         []types.String
+		wantFWConfig types.String
+		wantArgs     []types.String
+	}{
+		{
+			name:         "unset",
+			fwConfig:     types.StringNull(),
+			wantFWConfig: types.StringNull(),
+		},
+		{
+			name:         "unset keeps empty args",
+			fwConfig:     types.StringValue("name=opt/a,string=1"),
+			args:         stringValues(),
+			wantFWConfig: types.StringNull(),
+			wantArgs:     stringValues(),
+		},
+		{
+			name:         "only fw_cfg keeps empty args",
+			in:           str("-fw_cfg name=opt/a,string=1"),
+			fwConfig:     types.StringValue("name=opt/a,string=1"),
+			args:         stringValues(),
+			wantFWConfig: types.StringValue("name=opt/a,string=1"),
+			wantArgs:     stringValues(),
+		},
+		{
+			name:         "unmanaged fw_config stays in args",
+			in:           str("-fw_cfg name=opt/a,string=1 -cpu host"),
+			fwConfig:     types.StringNull(),
+			wantFWConfig: types.StringNull(),
+			wantArgs:     stringValues("-fw_cfg", "name=opt/a,string=1", "-cpu", "host"),
+		},
+		{
+			name:         "further fw_cfg entries stay in args",
+			in:           str("-fw_cfg name=opt/a,string=1 -cpu host -fw_cfg 'name=opt/b,string=two words'"),
+			fwConfig:     types.StringValue("old"),
+			wantFWConfig: types.StringValue("name=opt/a,string=1"),
+			wantArgs:     stringValues("-cpu", "host", "-fw_cfg", "name=opt/b,string=two words"),
+		},
+		{
+			name:         "missing fw_cfg",
+			in:           str("-cpu host"),
+			fwConfig:     types.StringValue("name=opt/a,string=1"),
+			wantFWConfig: types.StringNull(),
+			wantArgs:     stringValues("-cpu", "host"),
+		},
+		{
+			name:         "trailing fw_cfg without a value",
+			in:           str("-cpu host -fw_cfg"),
+			fwConfig:     types.StringValue("name=opt/a,string=1"),
+			wantFWConfig: types.StringNull(),
+			wantArgs:     stringValues("-cpu", "host", "-fw_cfg"),
+		},
+		{
+			name:         "quoted args",
+			in:           str(`-smbios 'type=0,vendor=it'\''s' -name "my vm"`),
+			fwConfig:     types.StringNull(),
+			args:         stringValues("-smbios"),
+			wantFWConfig: types.StringNull(),
+			wantArgs:     stringValues("-smbios", "type=0,vendor=it's", "-name", "my vm"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fwConfig, args := parseArgs(tt.in, tt.fwConfig, tt.args)
+			if !fwConfig.Equal(tt.wantFWConfig) {
+				t.Errorf("fw_config = %v, want %v", fwConfig, tt.wantFWConfig)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestArgsRoundTrip(t *testing.T) {
+	fwConfig := types.StringValue("name=opt/a,string=hello world")
+	args := stringValues("-fw_cfg", "name=opt/b,file=/tmp/b", "-cpu", "host,+aes", "-name", `it's "quoted"`, "")
+
+	in := argsString(fwConfig, args)
+	gotFWConfig, gotArgs := parseArgs(&in, fwConfig, args)
+	if !gotFWConfig.Equal(fwConfig) {
+		t.Errorf("fw_config = %v, want %v", gotFWConfig, fwConfig)
+	}
+	if !reflect.DeepEqual(gotArgs, args) {
+		t.Errorf("args = %v, want %v", gotArgs, args)
+	}
+}
@@ -0,0 +1,395 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/FreekingDean/proxmox-api-go/proxmox"
+	"github.com/FreekingDean/proxmox-api-go/proxmox/nodes/qemu/snapshot"
+
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/apierr"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/retry"
+	"github.com/FreekingDean/terraform-provider-proxmox/internal/tasks"
+)
+
+type resourceNodeVirtualMachineSnapshotModel struct {
+	ID               types.String `tfsdk:"id"`
+	Node             types.String `tfsdk:"node"`
+	VMID             types.Int64  `tfsdk:"vmid"`
+	Name             types.String `tfsdk:"name"`
+	Description      types.String `tfsdk:"description"`
+	IncludeRAM       types.Bool   `tfsdk:"include_ram"`
+	RollbackOnCreate types.Bool   `tfsdk:"rollback_on_create"`
+}
+
+type resourceNodeVirtualMachineSnapshot struct {
+	t *tasks.Client
+	s *snapshot.Client
+}
+
+func (r *resourceNodeVirtualMachineSnapshot) SetClient(p apiClient) {
+	r.t = tasks.New(p)
+	r.s = snapshot.New(p)
+}
+
+func (r *resourceNodeVirtualMachineSnapshot) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_node_virtual_machine_snapshot"
+}
+
+func (r *resourceNodeVirtualMachineSnapshot) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A snapshot of a virtual machine",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The snapshot identifier (node@vmid/name)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"node": schema.StringAttribute{
+				Required:    true,
+				Description: "The node the VM is on",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vmid": schema.Int64Attribute{
+				Required:    true,
+				Description: "The vmid of the VM to snapshot",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the snapshot",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of the snapshot",
+			},
+			"include_ram": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Include the VM's RAM in the snapshot (default: false)",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"rollback_on_create": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If a snapshot with this name already exists roll the VM back to it instead of failing to create the snapshot. An existing snapshot adopted this way is kept when the resource is destroyed",
+			},
+		},
+	}
+}
+
+func (r *resourceNodeVirtualMachineSnapshot) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan resourceNodeVirtualMachineSnapshotModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	node := plan.Node.ValueString()
+	vmid := int(plan.VMID.ValueInt64())
+
+	existing, err := r.find(ctx, node, vmid, plan.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error listing VM snapshots",
+			"An unexpected error occurred when listing the VM snapshots. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+
+	if existing != nil && plan.RollbackOnCreate.ValueBool() {
+		err = retry.OnLock(ctx, "rollback VM snapshot", func() error {
+			task, err := r.s.Rollback(ctx, snapshot.RollbackRequest{
+				Node:     node,
+				Vmid:     vmid,
+				Snapname: plan.Name.ValueString(),
+			})
+			if err != nil {
+				return err
+			}
+			return r.t.WaitForExit(ctx, task, node)
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error rolling back VM snapshot",
+				"An unexpected error occurred when rolling back the VM to the snapshot. "+
+					"Proxmox API Error: "+err.Error(),
+			)
+			return
+		}
+
+		// The snapshot was not created by terraform, remember to keep it
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateAdopted, []byte("true"))...)
+
+		if !plan.Description.IsNull() && !sameDescription(existing.Description, plan.Description.ValueString()) {
+			err = r.s.UpdateSnapshotConfig(ctx, snapshot.UpdateSnapshotConfigRequest{
+				Node:        node,
+				Vmid:        vmid,
+				Snapname:    plan.Name.ValueString(),
+				Description: proxmox.String(plan.Description.ValueString()),
+			})
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error updating VM snapshot",
+					"An unexpected error occurred when updating the VM snapshot. "+
+						"Proxmox API Error: "+err.Error(),
+				)
+				return
+			}
+		}
+	} else {
+		creq := snapshot.CreateRequest{
+			Node:     node,
+			Vmid:     vmid,
+			Snapname: plan.Name.ValueString(),
+		}
+		if plan.Description.ValueString() != "" {
+			creq.Description = proxmox.String(plan.Description.ValueString())
+		}
+		if !plan.IncludeRAM.IsNull() {
+			creq.Vmstate = proxmox.PVEBool(plan.IncludeRAM.ValueBool())
+		}
+
+		err = retry.OnLock(ctx, "create VM snapshot", func() error {
+			task, err := r.s.Create(ctx, creq)
+			if err != nil {
+				return err
+			}
+			return r.t.WaitForExit(ctx, task, node)
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error creating VM snapshot",
+				"An unexpected error occurred when creating the VM snapshot. "+
+					"Proxmox API Error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	plan.ID = types.StringValue(snapshotID(node, vmid, plan.Name.ValueString()))
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *resourceNodeVirtualMachineSnapshot) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data resourceNodeVirtualMachineSnapshotModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	adopted, diags := req.Private.GetKey(ctx, privateAdopted)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if string(adopted) == "true" {
+		tflog.Info(ctx, "Keeping adopted VM snapshot", map[string]interface{}{
+			"id": data.ID.ValueString(),
+		})
+		return
+	}
+
+	err := retry.OnLock(ctx, "delete VM snapshot", func() error {
+		task, err := r.s.Delete(ctx, snapshot.DeleteRequest{
+			Node:     data.Node.ValueString(),
+			Vmid:     int(data.VMID.ValueInt64()),
+			Snapname: data.Name.ValueString(),
+		})
+		if err != nil {
+			return err
+		}
+		return r.t.WaitForExit(ctx, task, data.Node.ValueString())
+	})
+	if err != nil {
+		if apierr.Is(err, apierr.ErrNotFound) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error deleting VM snapshot",
+			"An unexpected error occurred when deleting the VM snapshot. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *resourceNodeVirtualMachineSnapshot) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan resourceNodeVirtualMachineSnapshotModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state resourceNodeVirtualMachineSnapshotModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.Description.Equal(state.Description) {
+		err := r.s.UpdateSnapshotConfig(ctx, snapshot.UpdateSnapshotConfigRequest{
+			Node:        plan.Node.ValueString(),
+			Vmid:        int(plan.VMID.ValueInt64()),
+			Snapname:    plan.Name.ValueString(),
+			Description: proxmox.String(plan.Description.ValueString()),
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error updating VM snapshot",
+				"An unexpected error occurred when updating the VM snapshot. "+
+					"Proxmox API Error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *resourceNodeVirtualMachineSnapshot) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state resourceNodeVirtualMachineSnapshotModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	snap, err := r.find(ctx, state.Node.ValueString(), int(state.VMID.ValueInt64()), state.Name.ValueString())
+	if apierr.Is(err, apierr.ErrNotFound) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error listing VM snapshots",
+			"An unexpected error occurred when listing the VM snapshots. "+
+				"Proxmox API Error: "+err.Error(),
+		)
+		return
+	}
+	if snap == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = types.StringValue(snapshotID(state.Node.ValueString(), int(state.VMID.ValueInt64()), snap.Name))
+	if !sameDescription(snap.Description, state.Description.ValueString()) &&
+		(snap.Description != "" || !state.Description.IsNull()) {
+		state.Description = types.StringValue(snap.Description)
+	}
+	state.IncludeRAM = optionalBool(state.IncludeRAM, (*bool)(snap.Vmstate), false)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *resourceNodeVirtualMachineSnapshot) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "@")
+	if len(parts) != 2 {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: node@vmid/name, got: %q", req.ID),
+		)
+		return
+	}
+	node := parts[0]
+	parts = strings.SplitN(parts[1], "/", 2)
+	if len(parts) != 2 {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: node@vmid/name, got: %q", req.ID),
+		)
+		return
+	}
+	vmid, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected a numeric vmid in import identifier, got: %q", parts[0]),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(
+		resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...,
+	)
+	resp.Diagnostics.Append(
+		resp.State.SetAttribute(ctx, path.Root("node"), node)...,
+	)
+	resp.Diagnostics.Append(
+		resp.State.SetAttribute(ctx, path.Root("vmid"), vmid)...,
+	)
+	resp.Diagnostics.Append(
+		resp.State.SetAttribute(ctx, path.Root("name"), parts[1])...,
+	)
+}
+
+// find returns the named snapshot or nil if it does not exist.
+func (r *resourceNodeVirtualMachineSnapshot) find(ctx context.Context, node string, vmid int, name string) (*snapshot.IndexResponse, error) {
+	snapshots, err := r.s.Index(ctx, snapshot.IndexRequest{
+		Node: node,
+		Vmid: vmid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, snap := range snapshots {
+		if snap.Name == name {
+			return &snap, nil
+		}
+	}
+	return nil, nil
+}
+
+// sameDescription compares a description read from proxmox to a configured
+// one. proxmox stores descriptions as comment lines, a configured description
+// is read back with a trailing newline.
+func sameDescription(read string, configured string) bool {
+	return read == configured || read == configured+"\n"
+}
+
+// privateAdopted marks a snapshot which existed before it was managed
+const privateAdopted = "adopted"
+
+func snapshotID(node string, vmid int, name string) string {
+	return fmt.Sprintf("%s@%d/%s", node, vmid, name)
+}
@@ -0,0 +1,256 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func rawStateFromJSON(t *testing.T, in string) map[string]json.RawMessage {
+	t.Helper()
+	rawState := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(in), &rawState); err != nil {
+		t.Fatalf("invalid test state %q: %s", in, err)
+	}
+	return rawState
+}
+
+func assertRawState(t *testing.T, got map[string]json.RawMessage, want string) {
+	t.Helper()
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Compare decoded values so key order and spacing do not matter
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(gotJSON, &gotValue); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid test state %q: %s", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("state = %s, want %s", gotJSON, want)
+	}
+}
+
+func TestUpgradeGuestAgent(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "enabled",
+			in:   `{"id":100,"guest_agent":true}`,
+			want: `{"id":100,"agent":{"enabled":true,"type":null,"fstrim_cloned_disks":null,"freeze_fs_on_backup":null}}`,
+		},
+		{
+			name: "disabled",
+			in:   `{"id":100,"guest_agent":false}`,
+			want: `{"id":100,"agent":{"enabled":false,"type":null,"fstrim_cloned_disks":null,"freeze_fs_on_backup":null}}`,
+		},
+		{
+			name: "null",
+			in:   `{"id":100,"guest_agent":null}`,
+			want: `{"id":100}`,
+		},
+		{
+			name: "missing",
+			in:   `{"id":100}`,
+			want: `{"id":100}`,
+		},
+		{
+			name:    "invalid",
+			in:      `{"id":100,"guest_agent":"yes"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawState := rawStateFromJSON(t, tt.in)
+			err := upgradeGuestAgent(rawState)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertRawState(t, rawState, tt.want)
+		})
+	}
+}
+
+func TestUpgradeSerials(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "devices",
+			in:   `{"id":100,"serials":["socket","/dev/ttyS0"]}`,
+			want: `{"id":100,"serial":[{"device":"socket"},{"device":"/dev/ttyS0"}]}`,
+		},
+		{
+			name: "empty",
+			in:   `{"id":100,"serials":[]}`,
+			want: `{"id":100,"serial":[]}`,
+		},
+		{
+			name: "null",
+			in:   `{"id":100,"serials":null}`,
+			want: `{"id":100,"serial":[]}`,
+		},
+		{
+			name: "missing",
+			in:   `{"id":100}`,
+			want: `{"id":100}`,
+		},
+		{
+			name:    "invalid",
+			in:      `{"id":100,"serials":"socket"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawState := rawStateFromJSON(t, tt.in)
+			err := upgradeSerials(rawState)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertRawState(t, rawState, tt.want)
+		})
+	}
+}
+
+// testConfig builds a config for the schema of r, attributes missing from
+// values are null.
+func testConfig(t *testing.T, r resource.Resource, values map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+	resp := &resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	typ := resp.Schema.Type().TerraformType(context.Background())
+	return tfsdk.Config{Schema: resp.Schema, Raw: testObject(t, typ, values)}
+}
+
+// testObject builds an object of typ, attributes missing from values are
+// null.
+func testObject(t *testing.T, typ tftypes.Type, values map[string]tftypes.Value) tftypes.Value {
+	t.Helper()
+	obj, ok := typ.(tftypes.Object)
+	if !ok {
+		t.Fatalf("%s is not an object", typ)
+	}
+	vals := map[string]tftypes.Value{}
+	for name, attrType := range obj.AttributeTypes {
+		vals[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, v := range values {
+		if _, ok := obj.AttributeTypes[name]; !ok {
+			t.Fatalf("unknown attribute %q", name)
+		}
+		vals[name] = v
+	}
+	return tftypes.NewValue(obj, vals)
+}
+
+// testAttributeType returns the type of the attribute name in the schema of r.
+func testAttributeType(t *testing.T, r resource.Resource, name string) tftypes.Type {
+	t.Helper()
+	resp := &resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, resp)
+	typ := resp.Schema.Type().TerraformType(context.Background()).(tftypes.Object)
+	attrType, ok := typ.AttributeTypes[name]
+	if !ok {
+		t.Fatalf("unknown attribute %q", name)
+	}
+	return attrType
+}
+
+func TestValidateStartup(t *testing.T) {
+	r := &resourceNodeVirtualMachine{}
+	startupType := testAttributeType(t, r, "startup")
+	tests := []struct {
+		name    string
+		startup tftypes.Value
+		wantErr bool
+	}{
+		{
+			name:    "unset",
+			startup: tftypes.NewValue(startupType, nil),
+		},
+		{
+			name:    "empty",
+			startup: testObject(t, startupType, nil),
+			wantErr: true,
+		},
+		{
+			name: "order",
+			startup: testObject(t, startupType, map[string]tftypes.Value{
+				"order": tftypes.NewValue(tftypes.Number, 1),
+			}),
+		},
+		{
+			name: "unknown down",
+			startup: testObject(t, startupType, map[string]tftypes.Value{
+				"down": tftypes.NewValue(tftypes.Number, tftypes.UnknownValue),
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := resource.ValidateConfigRequest{
+				Config: testConfig(t, r, map[string]tftypes.Value{
+					"startup": tt.startup,
+				}),
+			}
+			resp := &resource.ValidateConfigResponse{}
+			r.ValidateConfig(context.Background(), req, resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Errorf("errors = %v, want error %t", resp.Diagnostics, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpgradeVirtualMachineStateV0(t *testing.T) {
+	upgrade := (&resourceNodeVirtualMachine{}).UpgradeState(context.Background())[0].StateUpgrader
+	req := resource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{
+			JSON: []byte(`{"id":100,"guest_agent":true,"serials":["socket"],"fw_config":"name=opt/a,string=1"}`),
+		},
+	}
+	resp := &resource.UpgradeStateResponse{}
+	upgrade(context.Background(), req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	assertRawState(t, rawStateFromJSON(t, string(resp.DynamicValue.JSON)), `{
+		"id": 100,
+		"agent": {"enabled": true, "type": null, "fstrim_cloned_disks": null, "freeze_fs_on_backup": null},
+		"serial": [{"device": "socket"}],
+		"fw_config": "name=opt/a,string=1"
+	}`)
+}
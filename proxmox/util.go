@@ -1,8 +1,17 @@
 package proxmox
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 const (
@@ -37,3 +46,124 @@ func strToGB(in string) (int64, error) {
 	size = size * sizeMap[denom]
 	return size / G, nil
 }
+
+// parsePropertyString splits a proxmox property string (key=value,key=value)
+// into a map, a value without a key is stored under defaultKey
+func parsePropertyString(in string, defaultKey string) map[string]string {
+	values := map[string]string{}
+	if in == "" {
+		return values
+	}
+	for _, part := range strings.Split(in, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 1 {
+			values[defaultKey] = kv[0]
+			continue
+		}
+		values[kv[0]] = kv[1]
+	}
+	return values
+}
+
+func pveBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// propertyBool returns the value of a boolean property, leaving the current
+// value null if it was never set and proxmox reports the default.
+func propertyBool(current types.Bool, values map[string]string, key string, def bool) types.Bool {
+	value := def
+	if v, ok := values[key]; ok {
+		value = v == "1"
+	}
+	return optionalBool(current, &value, def)
+}
+
+// propertyString returns the value of a string property or null if unset
+func propertyString(values map[string]string, key string) types.String {
+	if v, ok := values[key]; ok {
+		return types.StringValue(v)
+	}
+	return types.StringNull()
+}
+
+// propertyInt64 returns the value of an integer property or null if unset
+func propertyInt64(values map[string]string, key string) types.Int64 {
+	if v, ok := values[key]; ok {
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return types.Int64Value(i)
+		}
+	}
+	return types.Int64Null()
+}
+
+// optionalBool returns the value of an optional boolean option, leaving the
+// current value null if it was never set and proxmox reports the default.
+func optionalBool(current types.Bool, value *bool, def bool) types.Bool {
+	v := def
+	if value != nil {
+		v = *value
+	}
+	if !current.IsNull() || v != def {
+		return types.BoolValue(v)
+	}
+	return current
+}
+
+// optionalInt64 returns the value of an optional integer option, leaving the
+// current value null if it was never set and proxmox reports the default.
+func optionalInt64(current types.Int64, value *int, def int64) types.Int64 {
+	v := def
+	if value != nil {
+		v = int64(*value)
+	}
+	if !current.IsNull() || v != def {
+		return types.Int64Value(v)
+	}
+	return current
+}
+
+// privateImported marks a resource which was imported rather than created,
+// its creation only attributes are not known until the first apply.
+const privateImported = "imported"
+
+// requiresReplaceUnlessImported reports whether a changed creation only
+// attribute requires replacement. A null prior value on an imported resource
+// is recorded in place instead.
+func requiresReplaceUnlessImported(ctx context.Context, p interface {
+	GetKey(context.Context, string) ([]byte, diag.Diagnostics)
+}, state attr.Value) (bool, diag.Diagnostics) {
+	if !state.IsNull() {
+		return true, nil
+	}
+	imported, diags := p.GetKey(ctx, privateImported)
+	return string(imported) != "true", diags
+}
+
+// requiresReplaceUnlessImportedString replaces the resource when a creation
+// only attribute changes. Proxmox can not read these back, an imported
+// resource has no prior value so the configured one is recorded in place.
+func requiresReplaceUnlessImportedString() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(
+		func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+			resp.RequiresReplace, resp.Diagnostics = requiresReplaceUnlessImported(ctx, req.Private, req.StateValue)
+		},
+		"Changing this value replaces the resource unless it was imported.",
+		"Changing this value replaces the resource unless it was imported.",
+	)
+}
+
+// requiresReplaceUnlessImportedList is requiresReplaceUnlessImportedString
+// for list attributes.
+func requiresReplaceUnlessImportedList() planmodifier.List {
+	return listplanmodifier.RequiresReplaceIf(
+		func(ctx context.Context, req planmodifier.ListRequest, resp *listplanmodifier.RequiresReplaceIfFuncResponse) {
+			resp.RequiresReplace, resp.Diagnostics = requiresReplaceUnlessImported(ctx, req.Private, req.StateValue)
+		},
+		"Changing this value replaces the resource unless it was imported.",
+		"Changing this value replaces the resource unless it was imported.",
+	)
+}
@@ -0,0 +1,236 @@
+package proxmox
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestParsePropertyString(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		defaultKey string
+		want       map[string]string
+	}{
+		{
+			name:       "empty",
+			in:         "",
+			defaultKey: "host",
+			want:       map[string]string{},
+		},
+		{
+			name:       "default key only",
+			in:         "0000:01:00",
+			defaultKey: "host",
+			want:       map[string]string{"host": "0000:01:00"},
+		},
+		{
+			name:       "default key with options",
+			in:         "0000:01:00,pcie=1,x-vga=1",
+			defaultKey: "host",
+			want:       map[string]string{"host": "0000:01:00", "pcie": "1", "x-vga": "1"},
+		},
+		{
+			name:       "keyed values",
+			in:         "mapping=gpu,rombar=0",
+			defaultKey: "host",
+			want:       map[string]string{"mapping": "gpu", "rombar": "0"},
+		},
+		{
+			name:       "value containing equals",
+			in:         "virtio=BC:24:11:00:00:01,bridge=vmbr0,tag=10",
+			defaultKey: "model",
+			want:       map[string]string{"virtio": "BC:24:11:00:00:01", "bridge": "vmbr0", "tag": "10"},
+		},
+		{
+			name:       "split on first equals",
+			in:         "args=-a=b",
+			defaultKey: "x",
+			want:       map[string]string{"args": "-a=b"},
+		},
+		{
+			name:       "empty value",
+			in:         "enabled=1,type=",
+			defaultKey: "enabled",
+			want:       map[string]string{"enabled": "1", "type": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parsePropertyString(tt.in, tt.defaultKey)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePropertyString(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropertyBool(t *testing.T) {
+	tests := []struct {
+		name    string
+		current types.Bool
+		values  map[string]string
+		def     bool
+		want    types.Bool
+	}{
+		{
+			name:    "unset stays null",
+			current: types.BoolNull(),
+			values:  map[string]string{},
+			def:     false,
+			want:    types.BoolNull(),
+		},
+		{
+			name:    "default stays null",
+			current: types.BoolNull(),
+			values:  map[string]string{"pcie": "0"},
+			def:     false,
+			want:    types.BoolNull(),
+		},
+		{
+			name:    "non default is set",
+			current: types.BoolNull(),
+			values:  map[string]string{"pcie": "1"},
+			def:     false,
+			want:    types.BoolValue(true),
+		},
+		{
+			name:    "configured default is kept",
+			current: types.BoolValue(false),
+			values:  map[string]string{},
+			def:     false,
+			want:    types.BoolValue(false),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := propertyBool(tt.current, tt.values, "pcie", tt.def)
+			if !got.Equal(tt.want) {
+				t.Errorf("propertyBool() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakePrivate is an in memory private state.
+type fakePrivate map[string][]byte
+
+func (p fakePrivate) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p fakePrivate) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}
+
+func TestRequiresReplaceUnlessImported(t *testing.T) {
+	tests := []struct {
+		name    string
+		private fakePrivate
+		state   types.String
+		want    bool
+	}{
+		{
+			name:    "set",
+			private: fakePrivate{},
+			state:   types.StringValue("local:vztmpl/debian-11.tar.zst"),
+			want:    true,
+		},
+		{
+			name:    "set on imported",
+			private: fakePrivate{privateImported: []byte("true")},
+			state:   types.StringValue("local:vztmpl/debian-11.tar.zst"),
+			want:    true,
+		},
+		{
+			name:    "null on created",
+			private: fakePrivate{},
+			state:   types.StringNull(),
+			want:    true,
+		},
+		{
+			name:    "null on imported",
+			private: fakePrivate{privateImported: []byte("true")},
+			state:   types.StringNull(),
+			want:    false,
+		},
+		{
+			name:    "null after the import was applied",
+			private: fakePrivate{privateImported: []byte("false")},
+			state:   types.StringNull(),
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags := requiresReplaceUnlessImported(context.Background(), tt.private, tt.state)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if got != tt.want {
+				t.Errorf("requiresReplaceUnlessImported() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiresReplaceUnlessImportedString(t *testing.T) {
+	// Only null checks are done on the raw values, any object will do
+	existing := tftypes.NewValue(tftypes.Object{}, map[string]tftypes.Value{})
+
+	tests := []struct {
+		name  string
+		state types.String
+		plan  types.String
+		want  bool
+	}{
+		{
+			name:  "changed",
+			state: types.StringValue("local:vztmpl/debian-11.tar.zst"),
+			plan:  types.StringValue("local:vztmpl/debian-12.tar.zst"),
+			want:  true,
+		},
+		{
+			name:  "unchanged",
+			state: types.StringValue("local:vztmpl/debian-12.tar.zst"),
+			plan:  types.StringValue("local:vztmpl/debian-12.tar.zst"),
+			want:  false,
+		},
+		{
+			// Without the import marker a new value can not be applied in place
+			name:  "added",
+			state: types.StringNull(),
+			plan:  types.StringValue("local:vztmpl/debian-12.tar.zst"),
+			want:  true,
+		},
+		{
+			name:  "removed",
+			state: types.StringValue("local:vztmpl/debian-12.tar.zst"),
+			plan:  types.StringNull(),
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.StringRequest{
+				State:      tfsdk.State{Raw: existing},
+				Plan:       tfsdk.Plan{Raw: existing},
+				StateValue: tt.state,
+				PlanValue:  tt.plan,
+			}
+			resp := &planmodifier.StringResponse{PlanValue: tt.plan}
+			requiresReplaceUnlessImportedString().PlanModifyString(context.Background(), req, resp)
+			if resp.RequiresReplace != tt.want {
+				t.Errorf("RequiresReplace = %t, want %t", resp.RequiresReplace, tt.want)
+			}
+		})
+	}
+}